@@ -24,6 +24,8 @@ type application struct {
 	logger   zap.SugaredLogger
 	mailer   mailer.Client
 	auth     auth.AuthService
+	// outboxNotify - outbox'ga event yozilganini relay'ga bildiradi
+	outboxNotify chan struct{}
 }
 
 type config struct {
@@ -35,15 +37,40 @@ type config struct {
 	apiURL  string
 	app     appConfig
 	auth    authConfig
+	message messageConfig
+	storage storageConfig
 }
 
 type appConfig struct {
 	Audience string
 	Issuer   string
+	// origins - REST (CORS) va WebSocket uchun ruxsat berilgan origin'lar
+	origins *originPolicy
+}
+
+type messageConfig struct {
+	deleteWindow time.Duration
+	// eventRetention - user event oqimi qancha saqlanadi (0 - o'chirilmaydi)
+	eventRetention time.Duration
+}
+
+type storageConfig struct {
+	maxUploadSize    int64
+	allowedMIMETypes []string
 }
 
 type authConfig struct {
-	token tokenConfig
+	token  tokenConfig
+	ticket ticketConfig
+	// allowQueryToken - WebSocket/SSE ulanishida JWT'ni ?token= orqali qabul qilish (eski client'lar uchun)
+	allowQueryToken bool
+}
+
+type ticketConfig struct {
+	ttl    time.Duration
+	bindIP bool
+	// clientIPHeader - ishonchli proxy client IP'sini yozadigan header (bo'sh bo'lsa RemoteAddr)
+	clientIPHeader string
 }
 
 type tokenConfig struct {
@@ -75,22 +102,12 @@ type mailtrapConfig struct {
 	password string
 }
 
-var Upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		origin := r.Header.Get("Origin")
-		if origin == "" {
-			return true
-		}
-
-		switch origin {
-		case "http://localhost:8080", "http://127.0.0.1:8080":
-			return true
-		default:
-			return false
-		}
-	},
+func newUpgrader(origins *originPolicy) websocket.Upgrader {
+	return websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     origins.checkWebSocketOrigin,
+	}
 }
 
 func (app *application) mount() *chi.Mux {
@@ -101,6 +118,8 @@ func (app *application) mount() *chi.Mux {
 	r.Use(middleware.RequestID)
 
 	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(app.CORSMiddleware)
+
 		r.Get("/health", app.healthCheck)
 
 		docsURL := "/api/v1/swagger/doc.json"
@@ -116,18 +135,30 @@ func (app *application) mount() *chi.Mux {
 			})
 
 			r.With(app.AuthMiddleware).Get("/", app.GetUserHandler)
+			r.With(app.AuthMiddleware).Patch("/privacy", app.UpdatePrivacyHandler)
 		})
 
 		r.Group(func(r chi.Router) {
-			r.Use(app.AuthMiddleware)
+			r.Use(app.StreamAuthMiddleware)
 
 			r.Get("/ws", app.handleWebSocket)
+			r.Get("/events", app.EventStreamHandler)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(app.AuthMiddleware)
+
+			r.Post("/ws/ticket", app.IssueWSTicketHandler)
+			r.Get("/sync", app.SyncHandler)
 
 			r.Route("/chats", func(r chi.Router) {
 				r.Post("/", app.CreatechatHandler)
 				r.Get("/", app.GetUserChatsHandler)
 				r.Delete("/{chat_id}", app.DeleteChatHandler)
 				r.Get("/{chat_id}/messages", app.GetMessagesHandler)
+				r.Post("/{chat_id}/attachments", app.UploadAttachmentHandler)
+				r.Get("/{chat_id}/mentions/next", app.NextMentionHandler)
+				r.Patch("/{chat_id}/ttl", app.UpdateChatTTLHandler)
 			})
 
 			r.Route("/groups", func(r chi.Router) {
@@ -138,10 +169,21 @@ func (app *application) mount() *chi.Mux {
 				r.Delete("/{chat_id}/{user_id}/member", app.DeleteMemberHandler)
 			})
 
+			r.Get("/attachments/{id}", app.DownloadAttachmentHandler)
+			r.Get("/attachments/{id}/thumbnails/{size}", app.DownloadThumbnailHandler)
+
 			r.Route("/messages", func(r chi.Router) {
 				r.Post("/", app.MessageCreateHandler)
+				r.Get("/search", app.SearchMessagesHandler)
+				r.Get("/scheduled", app.GetScheduledMessagesHandler)
+				r.Delete("/scheduled/{id}", app.CancelScheduledMessageHandler)
 				r.Patch("/{id}", app.MessageUpdateHandler)
 				r.Delete("/{id}", app.MessageDeleteHandler)
+				r.Get("/{id}/thread", app.GetThreadHandler)
+				r.Get("/{id}/revisions", app.GetMessageRevisionsHandler)
+				r.Get("/{id}/receipts", app.GetMessageReceiptsHandler)
+				r.Post("/{id}/reactions", app.AddReactionHandler)
+				r.Delete("/{id}/reactions/{emoji}", app.RemoveReactionHandler)
 				r.Patch("/chats/{chat_id}/read", app.MarkAsReadHandler)
 			})
 
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"bufio"
+	"chatX/internal/blob"
+	"chatX/internal/store"
+	service "chatX/internal/usecase"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	attachmentFormField   = "file"
+	maxAttachmentNameLen  = 255
+	multipartOverheadSize = 64 * 1024
+)
+
+var (
+	errFileRequired      = errors.New("multipart field \"file\" is required")
+	errFileTooLarge      = errors.New("file is too large")
+	errMIMETypeForbidden = errors.New("file type is not allowed")
+)
+
+// UploadAttachmentHandler godoc
+//
+//	@Summary		Fayl yuklash
+//	@Description	Joriy foydalanuvchi chatga fayl yoki rasm yuklaydi (multipart/form-data, `file` maydoni).
+//	@Description	Qaytgan `id` keyin `POST /messages` dagi `attachment_ids` ichida yuboriladi.
+//	@Description	24 soat ichida hech qaysi xabarga bog'lanmagan fayl o'chiriladi.
+//	@Description	Fayl turi kontentdan aniqlanadi va config'dagi ruxsat etilgan MIME turlar bilan tekshiriladi.
+//	@Tags			attachments
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			Authorization	header		string				true	"Bearer token: Bearer <token>"
+//	@Param			chat_id			path		int					true	"Chat ID"
+//	@Param			file			formData	file				true	"Yuklanadigan fayl"
+//	@Success		201				{object}	map[string]any		"{"data":{"id":1,"file_name":"a.png","mime_type":"image/png","size_bytes":1024,"url":"/api/v1/attachments/1"}}"
+//	@Failure		400				{object}	map[string]string	"chat_id, fayl yoki fayl turi noto'g'ri, rasm metadata'sini tozalab bo'lmadi"
+//	@Failure		401				{object}	map[string]string	"Authorization Bearer token yuborilmagan yoki noto'g'ri"
+//	@Failure		403				{object}	map[string]string	"User chat a'zosi emas"
+//	@Failure		413				{object}	map[string]string	"Fayl hajmi limitdan katta"
+//	@Failure		500				{object}	map[string]string	"Ichki server xatosi"
+//	@Router			/chats/{chat_id}/attachments [post]
+func (app *application) UploadAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserfromContext(r)
+	if !ok {
+		app.unauthorizedError(w, r, errors.New("user not found in context"))
+		return
+	}
+
+	chatID, err := parsePathInt64(chi.URLParam(r, "chat_id"), "chat_id")
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	isMember, err := app.services.MemberSRV.IsMember(r.Context(), chatID, user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if !isMember {
+		app.forbiddenError(w, r, errors.New("user is not a member of this chat"))
+		return
+	}
+
+	maxSize := app.config.storage.maxUploadSize
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+multipartOverheadSize)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var part io.Reader
+	var fileName string
+	for {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if isMaxBytesError(err) {
+				app.payloadTooLargeError(w, r, errFileTooLarge)
+				return
+			}
+			app.badRequestError(w, r, err)
+			return
+		}
+		if p.FormName() == attachmentFormField {
+			part = p
+			fileName = sanitizeFileName(p.FileName())
+			break
+		}
+	}
+	if part == nil {
+		app.badRequestError(w, r, errFileRequired)
+		return
+	}
+
+	// MIME turi client yuborgan header'dan emas, kontentning o'zidan aniqlanadi
+	body := bufio.NewReaderSize(http.MaxBytesReader(w, io.NopCloser(part), maxSize), 512)
+	head, err := body.Peek(512)
+	if err != nil && err != io.EOF {
+		app.uploadError(w, r, err)
+		return
+	}
+	if len(head) == 0 {
+		app.badRequestError(w, r, errors.New("file is empty"))
+		return
+	}
+
+	mimeType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if !app.isAllowedMIMEType(mimeType) {
+		app.badRequestError(w, r, errMIMETypeForbidden)
+		return
+	}
+
+	attachment, err := app.services.MessageSRV.UploadAttachment(r.Context(), chatID, user.ID, fileName, mimeType, body)
+	if err != nil {
+		app.uploadError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusCreated, attachment); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// DownloadAttachmentHandler godoc
+//
+//	@Summary		Faylni yuklab olish
+//	@Description	Attachment kontentini qaytaradi. Faqat chat a'zolari yuklab olishi mumkin,
+//	@Description	hali xabarga bog'lanmagan faylni esa faqat uni yuklagan user ko'ra oladi.
+//	@Tags			attachments
+//	@Produce		octet-stream
+//	@Param			Authorization	header		string				true	"Bearer token: Bearer <token>"
+//	@Param			id				path		int					true	"Attachment ID"
+//	@Success		200				{file}		file				"Fayl kontenti"
+//	@Failure		400				{object}	map[string]string	"ID noto'g'ri"
+//	@Failure		401				{object}	map[string]string	"Authorization Bearer token yuborilmagan yoki noto'g'ri"
+//	@Failure		403				{object}	map[string]string	"User chat a'zosi emas"
+//	@Failure		404				{object}	map[string]string	"Fayl topilmadi"
+//	@Failure		500				{object}	map[string]string	"Ichki server xatosi"
+//	@Router			/attachments/{id} [get]
+func (app *application) DownloadAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	attachment, ok := app.authorizeAttachment(w, r)
+	if !ok {
+		return
+	}
+
+	content, err := app.services.MessageSRV.OpenAttachment(r.Context(), attachment.ID)
+	if err != nil {
+		app.blobOpenError(w, r, err)
+		return
+	}
+	defer content.Close()
+
+	disposition := "attachment"
+	if strings.HasPrefix(attachment.MimeType, "image/") {
+		disposition = "inline"
+	}
+
+	w.Header().Set("Content-Type", attachment.MimeType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.SizeBytes, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.FileName}))
+	app.streamBlob(w, content, attachment.ID)
+}
+
+// DownloadThumbnailHandler godoc
+//
+//	@Summary		Rasm thumbnail'ini olish
+//	@Description	Rasm attachment'ining server tomonida yaratilgan JPEG thumbnail'ini qaytaradi.
+//	@Description	O'lchamlar: `small` (160px), `medium` (640px). Ruxsat qoidalari faylni yuklab olish bilan bir xil.
+//	@Tags			attachments
+//	@Produce		jpeg
+//	@Param			Authorization	header		string				true	"Bearer token: Bearer <token>"
+//	@Param			id				path		int					true	"Attachment ID"
+//	@Param			size			path		string				true	"Thumbnail o'lchami"	Enums(small, medium)
+//	@Success		200				{file}		file				"JPEG thumbnail"
+//	@Failure		400				{object}	map[string]string	"ID noto'g'ri"
+//	@Failure		401				{object}	map[string]string	"Authorization Bearer token yuborilmagan yoki noto'g'ri"
+//	@Failure		403				{object}	map[string]string	"User chat a'zosi emas"
+//	@Failure		404				{object}	map[string]string	"Fayl yoki thumbnail topilmadi"
+//	@Failure		500				{object}	map[string]string	"Ichki server xatosi"
+//	@Router			/attachments/{id}/thumbnails/{size} [get]
+func (app *application) DownloadThumbnailHandler(w http.ResponseWriter, r *http.Request) {
+	attachment, ok := app.authorizeAttachment(w, r)
+	if !ok {
+		return
+	}
+
+	content, err := app.services.MessageSRV.OpenThumbnail(r.Context(), attachment.ID, chi.URLParam(r, "size"))
+	if err != nil {
+		app.blobOpenError(w, r, err)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", "image/jpeg")
+	app.streamBlob(w, content, attachment.ID)
+}
+
+// authorizeAttachment - attachment'ni topadi va joriy user uni ko'ra olishini tekshiradi.
+// false qaytsa javob allaqachon yozilgan bo'ladi.
+func (app *application) authorizeAttachment(w http.ResponseWriter, r *http.Request) (*service.StoredAttachment, bool) {
+	user, ok := getUserfromContext(r)
+	if !ok {
+		app.unauthorizedError(w, r, errors.New("user not found in context"))
+		return nil, false
+	}
+
+	id, err := parsePathInt64(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return nil, false
+	}
+
+	attachment, err := app.services.MessageSRV.GetAttachment(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.SqlNotfound):
+			app.notFoundError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return nil, false
+	}
+
+	isMember, err := app.services.MemberSRV.IsMember(r.Context(), attachment.ChatID, user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return nil, false
+	}
+	if !isMember {
+		app.forbiddenError(w, r, errors.New("user is not a member of this chat"))
+		return nil, false
+	}
+	if attachment.MessageID == nil && attachment.UploaderID != user.ID {
+		app.notFoundError(w, r, store.SqlNotfound)
+		return nil, false
+	}
+
+	return attachment, true
+}
+
+func (app *application) blobOpenError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, store.SqlNotfound), errors.Is(err, blob.ErrNotFound):
+		app.notFoundError(w, r, err)
+	default:
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) streamBlob(w http.ResponseWriter, content io.Reader, attachmentID int64) {
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, content); err != nil {
+		app.logger.Errorw("attachment stream failed", "error", err, "attachment_id", attachmentID)
+	}
+}
+
+// uploadError - upload paytidagi xatoni limit oshgan yoki boshqa holatga ajratadi
+func (app *application) uploadError(w http.ResponseWriter, r *http.Request, err error) {
+	if isMaxBytesError(err) {
+		app.payloadTooLargeError(w, r, errFileTooLarge)
+		return
+	}
+	if errors.Is(err, service.ErrUnsupportedImage) {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	app.internalServerError(w, r, err)
+}
+
+func isMaxBytesError(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+func (app *application) isAllowedMIMEType(mimeType string) bool {
+	allowed := app.config.storage.allowedMIMETypes
+	if len(allowed) == 0 {
+		return true
+	}
+
+	return slices.Contains(allowed, mimeType)
+}
+
+func sanitizeFileName(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		name = "file"
+	}
+
+	if len(name) > maxAttachmentNameLen {
+		name = name[len(name)-maxAttachmentNameLen:]
+	}
+
+	return name
+}
@@ -42,7 +42,7 @@ func (app *application) buildActivationURL(token string) string {
 //	@Accept			json
 //	@Produce		json
 //	@Param			payload	body		service.RequestRegister	true								"Registration payload"
-//	@Success		201		{object}	map[string]any			"{"data":{"message":"registration	successful"}}"
+//	@Success		201		{object}	map[string]any			"{"data":{"message":"registration successful"}}"
 //	@Failure		400		{object}	map[string]string		"Body/validation xatosi yoki email/username band"
 //	@Failure		500		{object}	map[string]string		"Ichki server xatosi"
 //	@Router			/users/authentication [post]
@@ -21,6 +21,10 @@ type createGroupRequest struct {
 	Description string  `json:"description" validate:"max=255"`
 }
 
+type updateChatTTLRequest struct {
+	TTLSeconds int `json:"ttl_seconds" validate:"gte=0,lte=31536000"`
+}
+
 type updateGroupRequest struct {
 	Name        string `json:"name" validate:"required,max=255"`
 	Description string `json:"description" validate:"max=255"`
@@ -134,6 +138,7 @@ func (app *application) CreateGroupHandler(w http.ResponseWriter, r *http.Reques
 //
 //	@Summary		Joriy user chatlari
 //	@Description	Joriy foydalanuvchiga tegishli private va group chatlar ro'yxatini qaytaradi.
+//	@Description	Private chatlarda suhbatdoshning `peer_id` va `presence` qiymatlari ham qaytadi.
 //	@Tags			chats
 //	@Produce		json
 //	@Param			Authorization	header		string				true	"Bearer token: Bearer <token>"
@@ -155,6 +160,12 @@ func (app *application) GetUserChatsHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	for _, chat := range chats {
+		if chat.PeerID != nil && chat.Presence != nil {
+			chat.Presence.Status = app.ws.Presence(strconv.FormatInt(*chat.PeerID, 10))
+		}
+	}
+
 	if err := app.jsonResponse(w, http.StatusOK, chats); err != nil {
 		app.internalServerError(w, r, err)
 	}
@@ -267,33 +278,79 @@ func (app *application) DeleteChatHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	memberUsers, err := app.services.MemberSRV.GetByChatID(r.Context(), chatID)
+	if err := app.services.ChatSRVC.DeleteChat(r.Context(), senderID, chatID); err != nil {
+		switch {
+		case errors.Is(err, store.SqlNotfound):
+			app.notFoundError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.notifyOutbox()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UpdateChatTTLHandler godoc
+//
+//	@Summary		Disappearing messages sozlamasi
+//	@Description	Chatdagi yangi xabarlar berilgan vaqtdan keyin o'chib ketishini sozlaydi. `ttl_seconds: 0` sozlamani o'chiradi.
+//	@Description	Groupda faqat owner/admin, private chatda esa ikkala ishtirokchi ham o'zgartira oladi.
+//	@Tags			chats
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token: Bearer <token>"
+//	@Param			chat_id			path		int						true	"Chat ID"
+//	@Param			payload			body		updateChatTTLRequest	true	"Xabar yashash muddati (sekund)"
+//	@Success		200				{object}	map[string]any			"{"data":{"result":"updated","ttl_seconds":86400}}"
+//	@Failure		400				{object}	map[string]string		"chat_id yoki body noto'g'ri"
+//	@Failure		401				{object}	map[string]string		"Authorization Bearer token yuborilmagan yoki noto'g'ri"
+//	@Failure		403				{object}	map[string]string		"Ruxsat yo'q"
+//	@Failure		404				{object}	map[string]string		"Chat topilmadi"
+//	@Failure		500				{object}	map[string]string		"Ichki server xatosi"
+//	@Router			/chats/{chat_id}/ttl [patch]
+func (app *application) UpdateChatTTLHandler(w http.ResponseWriter, r *http.Request) {
+	senderID, ok := getUserfromContext(r)
+	if !ok {
+		app.unauthorizedError(w, r, errors.New("user not found in context"))
+		return
+	}
+
+	chatID, err := parsePathInt64(chi.URLParam(r, "chat_id"), "chat_id")
 	if err != nil {
-		app.internalServerError(w, r, err)
+		app.badRequestError(w, r, err)
 		return
 	}
 
-	memberIDs := make([]string, len(memberUsers))
-	for i, user := range memberUsers {
-		memberIDs[i] = strconv.FormatInt(user.ID, 10)
+	var req updateChatTTLRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
 	}
 
-	if err := app.services.ChatSRVC.DeleteChat(r.Context(), chatID); err != nil {
+	if err := app.services.ChatSRVC.SetMessageTTL(r.Context(), senderID.ID, chatID, req.TTLSeconds); err != nil {
 		switch {
 		case errors.Is(err, store.SqlNotfound):
 			app.notFoundError(w, r, err)
+		case errors.Is(err, store.SqlForbidden):
+			app.forbiddenError(w, r, err)
 		default:
 			app.internalServerError(w, r, err)
 		}
 		return
 	}
 
-	deletedByName := senderID.UserName
-	if deletedByName == "" {
-		deletedByName = "Kimdir"
+	if err := app.jsonResponse(w, http.StatusOK, map[string]any{
+		"result":      "updated",
+		"ttl_seconds": req.TTLSeconds,
+	}); err != nil {
+		app.internalServerError(w, r, err)
 	}
-
-	go app.ws.BroadcastChatDelete(int64(chatID), senderID.ID, deletedByName, memberIDs)
-
-	w.WriteHeader(http.StatusNoContent)
 }
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const corsMaxAge = 10 * time.Minute
+
+var (
+	corsAllowedMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	corsAllowedHeaders = []string{"Authorization", "Content-Type", "Last-Event-ID"}
+	corsExposedHeaders = []string{"Content-Disposition"}
+)
+
+// originPolicy - allowed_origins ro'yxati. Har bir yozuv "scheme://host[:port]" ko'rinishida;
+// "https://*.example.com" example.com'ning istalgan subdomain'iga (example.com'ning o'ziga emas) mos keladi,
+// "*" esa har qanday origin'ga ruxsat beradi.
+type originPolicy struct {
+	any      bool
+	exact    map[string]bool
+	wildcard []wildcardOrigin
+}
+
+type wildcardOrigin struct {
+	scheme string
+	suffix string // ".example.com"
+	port   string
+}
+
+func newOriginPolicy(origins []string) (*originPolicy, error) {
+	p := &originPolicy{exact: make(map[string]bool)}
+
+	for _, raw := range origins {
+		origin := strings.ToLower(strings.TrimRight(strings.TrimSpace(raw), "/"))
+		if origin == "" {
+			continue
+		}
+		if origin == "*" {
+			p.any = true
+			continue
+		}
+
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			return nil, fmt.Errorf("invalid allowed origin %q: must be scheme://host[:port]", raw)
+		}
+
+		host := u.Hostname()
+		if suffix, ok := strings.CutPrefix(host, "*."); ok {
+			if suffix == "" || strings.Contains(suffix, "*") {
+				return nil, fmt.Errorf("invalid allowed origin %q: wildcard must be *.domain", raw)
+			}
+			p.wildcard = append(p.wildcard, wildcardOrigin{scheme: u.Scheme, suffix: "." + suffix, port: u.Port()})
+			continue
+		}
+		if strings.Contains(host, "*") {
+			return nil, fmt.Errorf("invalid allowed origin %q: wildcard must be *.domain", raw)
+		}
+
+		p.exact[u.Scheme+"://"+u.Host] = true
+	}
+
+	return p, nil
+}
+
+// allowed - Origin header'i ro'yxatga mos keladimi
+func (p *originPolicy) allowed(origin string) bool {
+	if p.any {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if p.exact[origin] {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	host := u.Hostname()
+	for _, w := range p.wildcard {
+		if u.Scheme == w.scheme && u.Port() == w.port && len(host) > len(w.suffix) && strings.HasSuffix(host, w.suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkWebSocketOrigin - Upgrader.CheckOrigin: Origin'siz (brauzer bo'lmagan) client'lar,
+// API bilan bir xil host'dan ochilgan web client va allowed_origins'dagi origin'lar qabul qilinadi
+func (p *originPolicy) checkWebSocketOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	return p.allowed(origin)
+}
+
+// CORSMiddleware - allowed_origins'dagi origin'larga REST API'ni boshqa domendan chaqirishga ruxsat beradi.
+// Auth Bearer header orqali, shuning uchun cookie (credentials) ruxsati berilmaydi.
+func (app *application) CORSMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := w.Header()
+		header.Add("Vary", "Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		if !app.config.app.origins.allowed(origin) {
+			if preflight {
+				app.forbiddenError(w, r, errors.New("origin not allowed"))
+				return
+			}
+			// Ruxsat header'larisiz javobni brauzer sahifaga bermaydi
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header.Set("Access-Control-Allow-Origin", origin)
+
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			header.Set("Access-Control-Allow-Methods", strings.Join(corsAllowedMethods, ", "))
+			header.Set("Access-Control-Allow-Headers", strings.Join(corsAllowedHeaders, ", "))
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(corsMaxAge.Seconds())))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		header.Set("Access-Control-Expose-Headers", strings.Join(corsExposedHeaders, ", "))
+		next.ServeHTTP(w, r)
+	})
+}
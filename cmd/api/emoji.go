@@ -0,0 +1,108 @@
+package main
+
+import (
+	"unicode/utf8"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	zwj                = '\u200D'
+	variationSelector  = '\uFE0F'
+	keycapMark         = '\u20E3'
+	tagBlackFlag       = '\U0001F3F4'
+	tagCancel          = '\U000E007F'
+	regionalIndicatorA = '\U0001F1E6'
+	regionalIndicatorZ = '\U0001F1FF'
+	skinToneLight      = '\U0001F3FB'
+	skinToneDark       = '\U0001F3FF'
+)
+
+// pictographicRanges - emoji sifatida ko'rsatiladigan belgilar (Unicode Extended_Pictographic'ning
+// soddalashtirilgan nusxasi). Regional indicator va teri rangi modifikatorlari alohida tekshiriladi.
+var pictographicRanges = [][2]rune{
+	{0x00A9, 0x00A9}, {0x00AE, 0x00AE}, {0x203C, 0x203C}, {0x2049, 0x2049},
+	{0x2122, 0x2122}, {0x2139, 0x2139}, {0x2194, 0x2199}, {0x21A9, 0x21AA},
+	{0x231A, 0x231B}, {0x2328, 0x2328}, {0x23CF, 0x23CF}, {0x23E9, 0x23F3},
+	{0x23F8, 0x23FA}, {0x24C2, 0x24C2}, {0x25AA, 0x25AB}, {0x25B6, 0x25B6},
+	{0x25C0, 0x25C0}, {0x25FB, 0x25FE}, {0x2600, 0x27BF}, {0x2934, 0x2935},
+	{0x2B05, 0x2B07}, {0x2B1B, 0x2B1C}, {0x2B50, 0x2B50}, {0x2B55, 0x2B55},
+	{0x3030, 0x3030}, {0x303D, 0x303D}, {0x3297, 0x3297}, {0x3299, 0x3299},
+	{0x1F000, 0x1F1E5}, {0x1F200, 0x1F3FA}, {0x1F400, 0x1FAFF}, {0x1FC00, 0x1FFFD},
+}
+
+func isPictographic(r rune) bool {
+	for _, rng := range pictographicRanges {
+		if r >= rng[0] && r <= rng[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= regionalIndicatorA && r <= regionalIndicatorZ
+}
+
+func isSkinTone(r rune) bool {
+	return r >= skinToneLight && r <= skinToneDark
+}
+
+// isEmoji - s bitta emoji (grapheme) ekanini tekshiradi: oddiy emoji (FE0F va teri rangi bilan),
+// bayroq (ikki regional indicator), keycap (1️⃣), tag bayroq (🏴 + tag'lar) yoki ZWJ zanjiri (👨‍👩‍👧).
+// Oddiy matn, bir nechta emoji yoki boshqa belgilar aralashmasi rad etiladi.
+func isEmoji(s string) bool {
+	runes := []rune(s)
+	if len(runes) == 0 || !utf8.ValidString(s) {
+		return false
+	}
+
+	// Bayroq: 🇺🇿
+	if len(runes) == 2 && isRegionalIndicator(runes[0]) && isRegionalIndicator(runes[1]) {
+		return true
+	}
+
+	// Keycap: [0-9#*] FE0F? 20E3
+	if c := runes[0]; (c >= '0' && c <= '9') || c == '#' || c == '*' {
+		rest := runes[1:]
+		if len(rest) > 0 && rest[0] == variationSelector {
+			rest = rest[1:]
+		}
+		return len(rest) == 1 && rest[0] == keycapMark
+	}
+
+	// Tag bayroq: 🏴 E0020..E007E... E007F
+	if runes[0] == tagBlackFlag && len(runes) > 2 && runes[len(runes)-1] == tagCancel {
+		for _, r := range runes[1 : len(runes)-1] {
+			if r < 0xE0020 || r > 0xE007E {
+				return false
+			}
+		}
+		return true
+	}
+
+	// ZWJ zanjiri: element (ZWJ element)*, element = pictographic FE0F? skin-tone?
+	for i := 0; ; {
+		if i >= len(runes) || !isPictographic(runes[i]) {
+			return false
+		}
+		i++
+		if i < len(runes) && runes[i] == variationSelector {
+			i++
+		}
+		if i < len(runes) && isSkinTone(runes[i]) {
+			i++
+		}
+		if i == len(runes) {
+			return true
+		}
+		if runes[i] != zwj {
+			return false
+		}
+		i++
+	}
+}
+
+func validateEmoji(fl validator.FieldLevel) bool {
+	return isEmoji(fl.Field().String())
+}
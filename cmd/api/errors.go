@@ -71,3 +71,14 @@ func (app *application) forbiddenError(w http.ResponseWriter, r *http.Request, e
 
 	writeJSONError(w, http.StatusForbidden, err.Error())
 }
+
+func (app *application) payloadTooLargeError(w http.ResponseWriter, r *http.Request, err error) {
+	app.logger.Errorw("payload too large",
+		"error", err,
+		"request_id", middleware.GetReqID(r.Context()),
+		"method", r.Method,
+		"path", r.URL.Path,
+	)
+
+	writeJSONError(w, http.StatusRequestEntityTooLarge, err.Error())
+}
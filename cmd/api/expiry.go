@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	expiredSweepInterval = 30 * time.Second
+	expiredSweepBatch    = 500
+
+	// orphanUploadTTL - yuklanib, shu vaqt ichida xabarga bog'lanmagan fayllar o'chiriladi
+	orphanUploadTTL           = 24 * time.Hour
+	orphanUploadSweepInterval = time.Hour
+	orphanUploadSweepBatch    = 200
+)
+
+// runExpiredMessageSweeper - disappearing messages: muddati o'tgan xabarlarni
+// o'chiradi; chat a'zolariga message_deleted eventi outbox orqali yuboriladi.
+// GetMessages muddati o'tganlarni o'zi filtrlaydi, shuning uchun sweeper kechiksa ham
+// eskirgan xabar clientga qaytmaydi.
+func (app *application) runExpiredMessageSweeper(ctx context.Context) {
+	ticker := time.NewTicker(expiredSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			app.sweepExpiredMessages(ctx)
+		}
+	}
+}
+
+func (app *application) sweepExpiredMessages(ctx context.Context) {
+	for {
+		expired, err := app.services.MessageSRV.DeleteExpired(ctx, expiredSweepBatch)
+		if err != nil {
+			app.logger.Errorw("expired message sweep failed",
+				"error", err,
+			)
+			return
+		}
+
+		if len(expired) > 0 {
+			app.notifyOutbox()
+		}
+
+		if len(expired) < expiredSweepBatch {
+			return
+		}
+	}
+}
+
+// runOrphanUploadSweeper - yuklangan, lekin hech qaysi xabarga bog'lanmagan fayllarni
+// orphanUploadTTL'dan keyin storage'dan o'chiradi
+func (app *application) runOrphanUploadSweeper(ctx context.Context) {
+	ticker := time.NewTicker(orphanUploadSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			app.sweepOrphanUploads(ctx)
+		}
+	}
+}
+
+func (app *application) sweepOrphanUploads(ctx context.Context) {
+	for {
+		deleted, err := app.services.MessageSRV.DeleteOrphanUploads(ctx, orphanUploadTTL, orphanUploadSweepBatch)
+		if err != nil {
+			app.logger.Errorw("orphan upload sweep failed",
+				"error", err,
+			)
+			return
+		}
+
+		if deleted == 0 {
+			return
+		}
+	}
+}
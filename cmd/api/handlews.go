@@ -20,12 +20,8 @@ func (app *application) handleWebSocket(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	client := &ws.Client{
-		ID:   strconv.FormatInt(senderID.ID, 10),
-		Hub:  app.ws,
-		Conn: conn,
-		Send: make(chan []byte, 256),
-	}
+	client := ws.NewClient(app.ws, strconv.FormatInt(senderID.ID, 10), conn)
+	client.Limiter = ws.NewRateLimiter(commandRateBurst, commandRateInterval)
 
 	client.Hub.Register <- client
 
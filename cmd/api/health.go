@@ -8,17 +8,21 @@ import (
 //
 //	@Summary		API holatini tekshirish
 //	@Description	API ishlayotganini tekshirish uchun texnik endpoint.
+//	@Description	`websocket` - shu instance'dagi ulanishlar, navbati to'lgani uchun yuborilmagan frame'lar
+//	@Description	va shu sababli uzilgan client'lar soni, `dropped_deliveries` - bufer to'lgani uchun yozilmagan
+//	@Description	"yetkazildi" signallari.
 //	@Tags			system
 //	@Produce		json
-//	@Success		200	{object}	map[string]string	"{"status":"available","version":"v1.0.0","message":"Welcome	to	ChatX	API","ENV":"dev"}"
+//	@Success		200	{object}	map[string]any		"{"status":"available","version":"v1.0.0","message":"Welcome to ChatX API","ENV":"dev","websocket":{...}}"
 //	@Failure		500	{object}	map[string]string	"Ichki server xatosi"
 //	@Router			/health [get]
 func (app *application) healthCheck(w http.ResponseWriter, r *http.Request) {
-	data := map[string]string{
-		"status":  "available",
-		"version": Version,
-		"message": "Welcome to ChatX API",
-		"ENV":     app.config.ENV,
+	data := map[string]any{
+		"status":    "available",
+		"version":   Version,
+		"message":   "Welcome to ChatX API",
+		"ENV":       app.config.ENV,
+		"websocket": app.ws.Stats(),
 	}
 	err := writeJSON(w, http.StatusOK, data)
 	if err != nil {
@@ -13,6 +13,10 @@ var Validate *validator.Validate
 
 func init() {
 	Validate = validator.New(validator.WithRequiredStructEnabled())
+	// emoji - reaksiyalar uchun bitta emoji (emoji.go)
+	if err := Validate.RegisterValidation("emoji", validateEmoji); err != nil {
+		panic(err)
+	}
 }
 
 func writeJSON(w http.ResponseWriter, status int, data any) error {
@@ -2,12 +2,14 @@ package main
 
 import (
 	"chatX/internal/auth"
+	"chatX/internal/blob"
 	"chatX/internal/db"
 	"chatX/internal/env"
 	"chatX/internal/mailer"
 	"chatX/internal/store"
 	service "chatX/internal/usecase"
 	"chatX/internal/ws"
+	"context"
 	"log"
 	"time"
 
@@ -40,6 +42,35 @@ func main() {
 		log.Fatalf("Error loading env: %v", err)
 	}
 
+	var deleteWindow time.Duration
+	if cfgEnv.Messages.DeleteForEveryoneWindow != "" {
+		deleteWindow, err = time.ParseDuration(cfgEnv.Messages.DeleteForEveryoneWindow)
+		if err != nil {
+			log.Fatalf("Error parsing delete_for_everyone_window: %v", err)
+		}
+	}
+
+	var eventRetention time.Duration
+	if cfgEnv.Messages.EventRetention != "" {
+		eventRetention, err = time.ParseDuration(cfgEnv.Messages.EventRetention)
+		if err != nil {
+			log.Fatalf("Error parsing event_retention: %v", err)
+		}
+	}
+
+	ticketTTL := 30 * time.Second
+	if cfgEnv.Auth.WSTicket.TTL != "" {
+		ticketTTL, err = time.ParseDuration(cfgEnv.Auth.WSTicket.TTL)
+		if err != nil {
+			log.Fatalf("Error parsing ws_ticket.ttl: %v", err)
+		}
+	}
+
+	origins, err := newOriginPolicy(cfgEnv.App.AllowedOrigins)
+	if err != nil {
+		log.Fatalf("Error parsing allowed_origins: %v", err)
+	}
+
 	addr := cfgEnv.Server.Port
 	if addr != "" && addr[0] != ':' {
 		addr = ":" + addr
@@ -58,7 +89,7 @@ func main() {
 			MaxIdletime:  cfgEnv.Database.MaxIdletime,
 		},
 		ENV:     cfgEnv.App.ENV,
-		Upgrade: Upgrader,
+		Upgrade: newUpgrader(origins),
 		apiURL:  cfgEnv.App.APIURL,
 		mail: MailConfig{
 			mailtrap: mailtrapConfig{
@@ -75,16 +106,32 @@ func main() {
 				secret: cfgEnv.Auth.SecretKey,
 				exp:    time.Hour * 24, //1 Day
 			},
+			ticket: ticketConfig{
+				ttl:            ticketTTL,
+				bindIP:         cfgEnv.Auth.WSTicket.BindIP,
+				clientIPHeader: cfgEnv.Auth.WSTicket.ClientIPHeader,
+			},
+			allowQueryToken: cfgEnv.Auth.AllowQueryToken,
 		},
 		app: appConfig{
 			Audience: cfgEnv.App.Audience,
 			Issuer:   cfgEnv.App.Issuer,
+			origins:  origins,
+		},
+		message: messageConfig{
+			deleteWindow:   deleteWindow,
+			eventRetention: eventRetention,
+		},
+		storage: storageConfig{
+			maxUploadSize:    cfgEnv.Storage.MaxUploadSize,
+			allowedMIMETypes: cfgEnv.Storage.AllowedMIMETypes,
 		},
 	}
 
 	logger := *zap.Must(zap.NewProduction()).Sugar()
 	defer logger.Sync()
 
+	dsn := db.DSN(cfg.DB.Addr, cfg.DB.Host, cfg.DB.User, cfg.DB.Password, cfg.DB.Name)
 	db, err := db.NewPostgres(
 		cfg.DB.Addr,
 		cfg.DB.Host,
@@ -106,10 +153,49 @@ func main() {
 	)
 
 	hub := ws.NewHub()
-	go hub.Run()
+	if cfgEnv.WebSocket.ReadTimeout != "" {
+		hub.Config.ReadTimeout, err = time.ParseDuration(cfgEnv.WebSocket.ReadTimeout)
+		if err != nil {
+			logger.Fatalw("Error parsing websocket read_timeout", "error", err)
+		}
+	}
+	if cfgEnv.WebSocket.WriteTimeout != "" {
+		hub.Config.WriteTimeout, err = time.ParseDuration(cfgEnv.WebSocket.WriteTimeout)
+		if err != nil {
+			logger.Fatalw("Error parsing websocket write_timeout", "error", err)
+		}
+	}
+	if cfgEnv.WebSocket.MaxFrameSize > 0 {
+		hub.Config.MaxFrameSize = cfgEnv.WebSocket.MaxFrameSize
+	}
+	if cfgEnv.WebSocket.SendBuffer > 0 {
+		hub.Config.SendBuffer = cfgEnv.WebSocket.SendBuffer
+	}
+	switch cfgEnv.Hub.Backplane {
+	case "", "memory":
+	case "postgres":
+		backplane := ws.NewPostgresBackplane(db, dsn)
+		backplane.OnError = func(msg string, err error) {
+			logger.Errorw(msg, "error", err)
+		}
+		hub.Backplane = backplane
+	default:
+		logger.Fatalw("Unsupported hub backplane", "backplane", cfgEnv.Hub.Backplane)
+	}
+
+	var blobStore blob.Store
+	switch cfgEnv.Storage.Driver {
+	case "", "local":
+		blobStore, err = blob.NewLocalStore(cfgEnv.Storage.LocalDir)
+		if err != nil {
+			logger.Fatalw("Error initializing blob storage", "error", err)
+		}
+	default:
+		logger.Fatalw("Unsupported storage driver", "driver", cfgEnv.Storage.Driver)
+	}
 
 	storage := store.NewStorage(db)
-	services := service.NewServices(storage)
+	services := service.NewServices(storage, blobStore)
 	mailer := mailer.NewMailtrap(
 		cfg.mail.mailtrap.host,
 		cfg.mail.mailtrap.port,
@@ -127,7 +213,29 @@ func main() {
 		logger:   logger,
 		mailer:   mailer,
 		auth:     authService,
+
+		outboxNotify: make(chan struct{}, 1),
+	}
+
+	hub.OnFrame = app.handleClientFrame
+	hub.LoadRoom = app.loadChatMembers
+	hub.Events = services.EventSRV
+	hub.OnError = func(msg string, err error) {
+		logger.Errorw(msg, "error", err)
 	}
+	// Run hook'lar o'rnatilgandan keyingina ishga tushadi: ular Run goroutine'ida o'qiladi
+	go hub.Run()
+	if err := hub.StartBackplane(context.Background()); err != nil {
+		logger.Fatalw("Error starting hub backplane", "error", err)
+	}
+
+	go app.runScheduledDispatcher(context.Background())
+	go app.runExpiredMessageSweeper(context.Background())
+	go app.runOrphanUploadSweeper(context.Background())
+	go app.runDeliveryRecorder(context.Background())
+	go app.runPresenceRecorder(context.Background())
+	go app.runEventPruner(context.Background())
+	go app.runOutboxRelay(context.Background())
 
 	handler := app.mount()
 
@@ -18,6 +18,7 @@ type addMemberRequest struct {
 //
 //	@Summary		Chat a'zolarini olish
 //	@Description	Berilgan group chat uchun a'zolar ro'yxatini qaytaradi. Faqat chat a'zosi ko'ra oladi.
+//	@Description	Har bir a'zo uchun `presence` (`online`/`away`/`offline` va `last_seen_at`) qo'shiladi.
 //	@Tags			members
 //	@Produce		json
 //	@Param			Authorization	header		string				true	"Bearer token: Bearer <token>"
@@ -57,7 +58,13 @@ func (app *application) GetMembersHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if err := app.jsonResponse(w, http.StatusOK, members); err != nil {
+	result, err := app.withPresence(r.Context(), members)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, result); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
@@ -117,37 +124,7 @@ func (app *application) AddMemberHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	memberUsers, err := app.services.MemberSRV.GetByChatID(r.Context(), chatID)
-	if err != nil {
-		app.internalServerError(w, r, err)
-		return
-	}
-
-	memberIDs := make([]string, len(memberUsers))
-	addedUsername := ""
-	for i, user := range memberUsers {
-		memberIDs[i] = strconv.FormatInt(user.ID, 10)
-		if user.ID == req.UserID {
-			addedUsername = user.UserName
-		}
-	}
-	if addedUsername == "" {
-		addedUsername = "foydalanuvchi"
-	}
-
-	addedByName := senderID.UserName
-	if addedByName == "" {
-		addedByName = "Kimdir"
-	}
-
-	go app.ws.BroadcastMemberAdded(
-		int64(chatID),
-		req.UserID,
-		senderID.ID,
-		addedUsername,
-		addedByName,
-		memberIDs,
-	)
+	app.notifyOutbox()
 
 	if err := app.jsonResponse(w, http.StatusCreated, map[string]any{
 		"result":  "added",
@@ -203,5 +180,7 @@ func (app *application) DeleteMemberHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	app.notifyOutbox()
+
 	w.WriteHeader(http.StatusNoContent)
 }
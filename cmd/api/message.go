@@ -6,15 +6,24 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
 
 type createMessageRequest struct {
-	ChatID      int64  `json:"chat_id" validate:"required,gt=0"`
-	MessageText string `json:"message_text" validate:"required,max=4000"`
+	ChatID           int64      `json:"chat_id" validate:"required,gt=0"`
+	MessageText      string     `json:"message_text" validate:"required_without=AttachmentIDs,max=4000"`
+	ReplyToMessageID *int64     `json:"reply_to_message_id" validate:"omitempty,gt=0"`
+	SendAt           *time.Time `json:"send_at"`
+	AttachmentIDs    []int64    `json:"attachment_ids" validate:"omitempty,max=10,dive,gt=0"`
 }
 
+const (
+	deleteScopeMe       = "me"
+	deleteScopeEveryone = "everyone"
+)
+
 type updateMessageRequest struct {
 	MessageText string `json:"message_text" validate:"required,max=4000"`
 }
@@ -31,13 +40,17 @@ func parsePathInt64(raw string, paramName string) (int64, error) {
 //
 //	@Summary		Xabar yuborish
 //	@Description	Joriy foydalanuvchi berilgan chatga yangi xabar yuboradi.
+//	@Description	`reply_to_message_id` berilsa xabar shu chatdagi boshqa xabarga javob sifatida saqlanadi.
+//	@Description	`send_at` (RFC3339) berilsa xabar rejalashtiriladi va 202 bilan pending holatda qaytadi.
+//	@Description	`attachment_ids` oldin `/chats/{chat_id}/attachments` orqali yuklangan fayllarni xabarga bog'laydi.
 //	@Tags			messages
 //	@Accept			json
 //	@Produce		json
 //	@Param			Authorization	header		string					true	"Bearer token: Bearer <token>"
 //	@Param			payload			body		createMessageRequest	true	"Xabar yuborish ma'lumotlari"
 //	@Success		201				{object}	map[string]any			"{"data":{...xabar...}}"
-//	@Failure		400				{object}	map[string]string		"Body noto'g'ri"
+//	@Success		202				{object}	map[string]any			"{"data":{...rejalashtirilgan xabar...}}"
+//	@Failure		400				{object}	map[string]string		"Body, reply_to_message_id, send_at yoki attachment_ids noto'g'ri"
 //	@Failure		401				{object}	map[string]string		"Authorization Bearer token yuborilmagan yoki noto'g'ri"
 //	@Failure		403				{object}	map[string]string		"User chat a'zosi emas"
 //	@Failure		500				{object}	map[string]string		"Ichki server xatosi"
@@ -59,47 +72,126 @@ func (app *application) MessageCreateHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	isMember, err := app.services.MemberSRV.IsMember(r.Context(), req.ChatID, senderID.ID)
-	if err != nil {
-		app.internalServerError(w, r, err)
-		return
-	}
-	if !isMember {
-		app.forbiddenError(w, r, errors.New("user is not a member of this chat"))
+	if req.SendAt != nil {
+		scheduled, err := app.scheduleMessage(r.Context(), senderID.ID, req)
+		if err != nil {
+			app.messageOpError(w, r, err)
+			return
+		}
+
+		if err := app.jsonResponse(w, http.StatusAccepted, scheduled); err != nil {
+			app.internalServerError(w, r, err)
+		}
 		return
 	}
 
-	msg, err := app.services.MessageSRV.Create(r.Context(), service.Message{
-		ChatID:      req.ChatID,
-		SenderID:    senderID.ID,
-		MessageText: req.MessageText,
-	})
+	msg, err := app.createMessage(r.Context(), senderID.ID, req)
 	if err != nil {
-		app.internalServerError(w, r, err)
+		app.messageOpError(w, r, err)
 		return
 	}
 
-	memberUsers, err := app.services.MemberSRV.GetByChatID(r.Context(), int(msg.ChatID))
-	if err != nil {
+	if err := app.jsonResponse(w, http.StatusCreated, msg); err != nil {
 		app.internalServerError(w, r, err)
-		return
+	}
+}
+
+// broadcastNewMessage - yangi xabarni chat a'zolariga WebSocket orqali tarqatadi (outbox relay chaqiradi)
+func (app *application) broadcastNewMessage(msg *service.Message) error {
+	// Xabar yuborilgach yozish holati darhol tugaydi
+	app.ws.StopTyping(msg.ChatID, strconv.FormatInt(msg.SenderID, 10))
+
+	err := app.ws.BroadcastChatMessage(
+		msg.ChatID,
+		msg.ID,
+		msg.ChatName,
+		strconv.FormatInt(msg.SenderID, 10),
+		msg.SenderName,
+		msg.MessageText,
+		msg.ReplyToMessageID,
+		msg.Attachments,
+	)
+	if err != nil || len(msg.Mentions) == 0 {
+		return err
 	}
 
-	memberIDs := make([]string, len(memberUsers))
-	for i, user := range memberUsers {
-		memberIDs[i] = strconv.FormatInt(user.ID, 10)
+	mentionedIDs := make([]string, len(msg.Mentions))
+	for i, m := range msg.Mentions {
+		mentionedIDs[i] = strconv.FormatInt(m.UserID, 10)
 	}
 
-	go app.ws.BroadcastChatMessage(
+	return app.ws.BroadcastMention(
 		msg.ChatID,
+		msg.ID,
 		msg.ChatName,
 		strconv.FormatInt(msg.SenderID, 10),
 		msg.SenderName,
 		msg.MessageText,
-		memberIDs,
+		mentionedIDs,
 	)
+}
 
-	if err := app.jsonResponse(w, http.StatusCreated, msg); err != nil {
+// NextMentionHandler godoc
+//
+//	@Summary		Keyingi o'qilmagan mention
+//	@Description	Joriy user mention qilingan va hali o'qilmagan keyingi xabar ID sini qaytaradi.
+//	@Description	`after` berilsa shu xabar ID dan keyingilari qidiriladi. Topilgan ID bilan `GET /chats/{chat_id}/messages?around=` chaqiriladi.
+//	@Tags			messages
+//	@Produce		json
+//	@Param			Authorization	header		string				true	"Bearer token: Bearer <token>"
+//	@Param			chat_id			path		int					true	"Chat ID"
+//	@Param			after			query		int					false	"Shu xabar ID dan keyingi mention"
+//	@Success		200				{object}	map[string]any		"{"data":{"message_id":42}}"
+//	@Failure		400				{object}	map[string]string	"chat_id yoki after noto'g'ri"
+//	@Failure		401				{object}	map[string]string	"Authorization Bearer token yuborilmagan yoki noto'g'ri"
+//	@Failure		403				{object}	map[string]string	"User chat a'zosi emas"
+//	@Failure		404				{object}	map[string]string	"O'qilmagan mention yo'q"
+//	@Failure		500				{object}	map[string]string	"Ichki server xatosi"
+//	@Router			/chats/{chat_id}/mentions/next [get]
+func (app *application) NextMentionHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserfromContext(r)
+	if !ok {
+		app.unauthorizedError(w, r, errors.New("user not found in context"))
+		return
+	}
+
+	chatID, err := parsePathInt64(chi.URLParam(r, "chat_id"), "chat_id")
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var afterID int64
+	if raw := r.URL.Query().Get("after"); raw != "" {
+		afterID, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil || afterID < 0 {
+			app.badRequestError(w, r, errors.New("after must be a non-negative integer"))
+			return
+		}
+	}
+
+	isMember, err := app.services.MemberSRV.IsMember(r.Context(), chatID, user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if !isMember {
+		app.forbiddenError(w, r, errors.New("user is not a member of this chat"))
+		return
+	}
+
+	msgID, err := app.services.MessageSRV.NextUnreadMention(r.Context(), chatID, user.ID, afterID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.SqlNotfound):
+			app.notFoundError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, map[string]int64{"message_id": msgID}); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
@@ -107,13 +199,19 @@ func (app *application) MessageCreateHandler(w http.ResponseWriter, r *http.Requ
 // GetMessagesHandler godoc
 //
 //	@Summary		Chat xabarlarini olish
-//	@Description	Berilgan chatdagi xabarlar tarixini qaytaradi. Faqat chat a'zosi ko'ra oladi.
+//	@Description	Berilgan chatdagi xabarlar tarixini cursor pagination bilan qaytaradi. Faqat chat a'zosi ko'ra oladi.
+//	@Description	`before`, `after` va `around` dan faqat bittasi yuboriladi. Hech biri bo'lmasa eng oxirgi xabarlar qaytadi.
+//	@Description	`prev_cursor` eski xabarlar uchun `before`, `next_cursor` yangi xabarlar uchun `after` qiymati sifatida ishlatiladi.
 //	@Tags			messages
 //	@Produce		json
 //	@Param			Authorization	header		string				true	"Bearer token: Bearer <token>"
 //	@Param			chat_id			path		int					true	"Chat ID"
-//	@Success		200				{object}	map[string]any		"{"data":[...xabarlar...]}"
-//	@Failure		400				{object}	map[string]string	"chat_id noto'g'ri"
+//	@Param			limit			query		int					false	"Sahifadagi xabarlar soni (1..100)"	default(50)
+//	@Param			before			query		int					false	"Shu xabar ID dan oldingi xabarlar"
+//	@Param			after			query		int					false	"Shu xabar ID dan keyingi xabarlar"
+//	@Param			around			query		int					false	"Shu xabar ID atrofidagi xabarlar"
+//	@Success		200				{object}	map[string]any		"{"data":{"messages":[...],"prev_cursor":10,"next_cursor":null}}"
+//	@Failure		400				{object}	map[string]string	"chat_id yoki query param noto'g'ri"
 //	@Failure		401				{object}	map[string]string	"Authorization Bearer token yuborilmagan yoki noto'g'ri"
 //	@Failure		403				{object}	map[string]string	"User chat a'zosi emas"
 //	@Failure		500				{object}	map[string]string	"Ichki server xatosi"
@@ -141,7 +239,22 @@ func (app *application) GetMessagesHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	msg, err := app.services.MessageSRV.GetByChatID(r.Context(), chatID)
+	cq := store.CursorQuery{
+		Limit: 50,
+	}
+
+	query, err := cq.Parse(r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(query); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	msg, err := app.services.MessageSRV.GetByChatID(r.Context(), chatID, senderID.ID, query)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -152,34 +265,50 @@ func (app *application) GetMessagesHandler(w http.ResponseWriter, r *http.Reques
 	}
 }
 
-// MarkAsReadHandler godoc
+// GetThreadHandler godoc
 //
-//	@Summary		Chatdagi xabarlarni o'qilgan deb belgilash
-//	@Description	Joriy foydalanuvchi uchun berilgan chatdagi barcha kiruvchi xabarlarni o'qilgan holatiga o'tkazadi.
+//	@Summary		Xabarga berilgan javoblar
+//	@Description	Root xabarga berilgan javoblarni cursor pagination bilan qaytaradi. Faqat chat a'zosi ko'ra oladi.
 //	@Tags			messages
 //	@Produce		json
 //	@Param			Authorization	header		string				true	"Bearer token: Bearer <token>"
-//	@Param			chat_id			path		int					true	"Chat ID"
-//	@Success		200				{object}	map[string]any		"{"data":{"status":"success"}}"
-//	@Failure		400				{object}	map[string]string	"chat_id noto'g'ri"
+//	@Param			id				path		int					true	"Root xabar ID"
+//	@Param			limit			query		int					false	"Sahifadagi xabarlar soni (1..100)"	default(50)
+//	@Param			before			query		int					false	"Shu xabar ID dan oldingi javoblar"
+//	@Param			after			query		int					false	"Shu xabar ID dan keyingi javoblar"
+//	@Param			around			query		int					false	"Shu xabar ID atrofidagi javoblar"
+//	@Success		200				{object}	map[string]any		"{"data":{"messages":[...],"prev_cursor":null,"next_cursor":null}}"
+//	@Failure		400				{object}	map[string]string	"ID yoki query param noto'g'ri"
 //	@Failure		401				{object}	map[string]string	"Authorization Bearer token yuborilmagan yoki noto'g'ri"
 //	@Failure		403				{object}	map[string]string	"User chat a'zosi emas"
+//	@Failure		404				{object}	map[string]string	"Xabar topilmadi"
 //	@Failure		500				{object}	map[string]string	"Ichki server xatosi"
-//	@Router			/messages/chats/{chat_id}/read [patch]
-func (app *application) MarkAsReadHandler(w http.ResponseWriter, r *http.Request) {
+//	@Router			/messages/{id}/thread [get]
+func (app *application) GetThreadHandler(w http.ResponseWriter, r *http.Request) {
 	senderID, ok := getUserfromContext(r)
 	if !ok {
 		app.unauthorizedError(w, r, errors.New("user not found in context"))
 		return
 	}
 
-	chatID, err := parsePathInt64(chi.URLParam(r, "chat_id"), "chat_id")
+	msgID, err := parsePathInt64(chi.URLParam(r, "id"), "id")
 	if err != nil {
 		app.badRequestError(w, r, err)
 		return
 	}
 
-	isMember, err := app.services.MemberSRV.IsMember(r.Context(), chatID, senderID.ID)
+	root, err := app.services.MessageSRV.GetByID(r.Context(), msgID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.SqlNotfound):
+			app.notFoundError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	isMember, err := app.services.MemberSRV.IsMember(r.Context(), root.ChatID, senderID.ID)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -189,27 +318,70 @@ func (app *application) MarkAsReadHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if err := app.services.MessageSRV.MarkChatAsRead(r.Context(), chatID, senderID.ID); err != nil {
-		app.internalServerError(w, r, err)
+	cq := store.CursorQuery{
+		Limit: 50,
+	}
+
+	query, err := cq.Parse(r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(query); err != nil {
+		app.badRequestError(w, r, err)
 		return
 	}
 
-	memberUsers, err := app.services.MemberSRV.GetByChatID(r.Context(), int(chatID))
+	replies, err := app.services.MessageSRV.GetThread(r.Context(), msgID, senderID.ID, query)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	readerID := strconv.FormatInt(senderID.ID, 10)
-	for _, user := range memberUsers {
-		recipientID := strconv.FormatInt(user.ID, 10)
-		if recipientID == readerID {
-			continue
-		}
-		go app.ws.BroadcastReadStatus(chatID, readerID, recipientID)
+	if err := app.jsonResponse(w, http.StatusOK, replies); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// MarkAsReadHandler godoc
+//
+//	@Summary		Chatdagi xabarlarni o'qilgan deb belgilash
+//	@Description	Joriy foydalanuvchi uchun berilgan chatdagi barcha kiruvchi xabarlarni o'qilgan holatiga o'tkazadi.
+//	@Description	Boshqa a'zolarga `messages_read` eventi `last_read_message_id` bilan yuboriladi.
+//	@Tags			messages
+//	@Produce		json
+//	@Param			Authorization	header		string				true	"Bearer token: Bearer <token>"
+//	@Param			chat_id			path		int					true	"Chat ID"
+//	@Success		200				{object}	map[string]any		"{"data":{"status":"success","last_read_message_id":42}}"
+//	@Failure		400				{object}	map[string]string	"chat_id noto'g'ri"
+//	@Failure		401				{object}	map[string]string	"Authorization Bearer token yuborilmagan yoki noto'g'ri"
+//	@Failure		403				{object}	map[string]string	"User chat a'zosi emas"
+//	@Failure		500				{object}	map[string]string	"Ichki server xatosi"
+//	@Router			/messages/chats/{chat_id}/read [patch]
+func (app *application) MarkAsReadHandler(w http.ResponseWriter, r *http.Request) {
+	senderID, ok := getUserfromContext(r)
+	if !ok {
+		app.unauthorizedError(w, r, errors.New("user not found in context"))
+		return
+	}
+
+	chatID, err := parsePathInt64(chi.URLParam(r, "chat_id"), "chat_id")
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	lastReadID, err := app.markChatRead(r.Context(), senderID.ID, chatID)
+	if err != nil {
+		app.messageOpError(w, r, err)
+		return
 	}
 
-	if err := app.jsonResponse(w, http.StatusOK, map[string]string{"status": "success"}); err != nil {
+	if err := app.jsonResponse(w, http.StatusOK, map[string]any{
+		"status":               "success",
+		"last_read_message_id": lastReadID,
+	}); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
@@ -217,14 +389,14 @@ func (app *application) MarkAsReadHandler(w http.ResponseWriter, r *http.Request
 // MessageUpdateHandler godoc
 //
 //	@Summary		Xabarni tahrirlash
-//	@Description	Joriy foydalanuvchi o'zi yuborgan xabar matnini yangilaydi.
+//	@Description	Joriy foydalanuvchi o'zi yuborgan xabar matnini yangilaydi. Eski matn tahrir tarixiga yoziladi.
 //	@Tags			messages
 //	@Accept			json
 //	@Produce		json
 //	@Param			Authorization	header		string					true	"Bearer token: Bearer <token>"
 //	@Param			id				path		int						true	"Xabar ID"
 //	@Param			payload			body		updateMessageRequest	true	"Yangilangan xabar matni"
-//	@Success		200				{object}	map[string]any			"{"data":{"result":"updated"}}"
+//	@Success		200				{object}	map[string]any			"{"data":{"result":"updated","edited_at":"..."}}"
 //	@Failure		400				{object}	map[string]string		"ID yoki body noto'g'ri"
 //	@Failure		401				{object}	map[string]string		"Authorization Bearer token yuborilmagan yoki noto'g'ri"
 //	@Failure		404				{object}	map[string]string		"Xabar topilmadi yoki userga tegishli emas"
@@ -253,18 +425,50 @@ func (app *application) MessageUpdateHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	msg, err := app.services.MessageSRV.GetByID(r.Context(), msgID)
+	editedAt, err := app.updateMessage(r.Context(), senderID.ID, msgID, req.MessageText)
 	if err != nil {
-		switch {
-		case errors.Is(err, store.SqlNotfound):
-			app.notFoundError(w, r, err)
-		default:
-			app.internalServerError(w, r, err)
-		}
+		app.messageOpError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, map[string]string{
+		"result":    "updated",
+		"edited_at": editedAt,
+	}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetMessageRevisionsHandler godoc
+//
+//	@Summary		Xabar tahrir tarixi
+//	@Description	Xabarning tahrirdan oldingi barcha matnlarini eskidan yangiga qaytaradi. Faqat chat a'zosi ko'ra oladi.
+//	@Tags			messages
+//	@Produce		json
+//	@Param			Authorization	header		string				true	"Bearer token: Bearer <token>"
+//	@Param			id				path		int					true	"Xabar ID"
+//	@Success		200				{object}	map[string]any		"{"data":[{"id":1,"message_text":"...","replaced_at":"..."}]}"
+//	@Failure		400				{object}	map[string]string	"ID noto'g'ri"
+//	@Failure		401				{object}	map[string]string	"Authorization Bearer token yuborilmagan yoki noto'g'ri"
+//	@Failure		403				{object}	map[string]string	"User chat a'zosi emas"
+//	@Failure		404				{object}	map[string]string	"Xabar topilmadi"
+//	@Failure		500				{object}	map[string]string	"Ichki server xatosi"
+//	@Router			/messages/{id}/revisions [get]
+func (app *application) GetMessageRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	senderID, ok := getUserfromContext(r)
+	if !ok {
+		app.unauthorizedError(w, r, errors.New("user not found in context"))
+		return
+	}
+
+	msgID, err := parsePathInt64(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		app.badRequestError(w, r, err)
 		return
 	}
 
-	if err := app.services.MessageSRV.UpdateMessage(r.Context(), msgID, senderID.ID, req.MessageText); err != nil {
+	msg, err := app.services.MessageSRV.GetByID(r.Context(), msgID)
+	if err != nil {
 		switch {
 		case errors.Is(err, store.SqlNotfound):
 			app.notFoundError(w, r, err)
@@ -274,20 +478,23 @@ func (app *application) MessageUpdateHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	memberUsers, err := app.services.MemberSRV.GetByChatID(r.Context(), int(msg.ChatID))
+	isMember, err := app.services.MemberSRV.IsMember(r.Context(), msg.ChatID, senderID.ID)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
-
-	memberIDs := make([]string, len(memberUsers))
-	for i, user := range memberUsers {
-		memberIDs[i] = strconv.FormatInt(user.ID, 10)
+	if !isMember {
+		app.forbiddenError(w, r, errors.New("user is not a member of this chat"))
+		return
 	}
 
-	go app.ws.BroadcastMessageUpdate(msg.ChatID, msgID, req.MessageText, memberIDs)
+	revisions, err := app.services.MessageSRV.GetRevisions(r.Context(), msgID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
 
-	if err := app.jsonResponse(w, http.StatusOK, map[string]string{"result": "updated"}); err != nil {
+	if err := app.jsonResponse(w, http.StatusOK, revisions); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
@@ -295,15 +502,19 @@ func (app *application) MessageUpdateHandler(w http.ResponseWriter, r *http.Requ
 // MessageDeleteHandler godoc
 //
 //	@Summary		Xabarni o'chirish
-//	@Description	Joriy foydalanuvchi o'zi yuborgan xabarni o'chiradi.
+//	@Description	`scope=me` xabarni faqat joriy foydalanuvchi uchun yashiradi, boshqa a'zolarga ta'sir qilmaydi.
+//	@Description	`scope=everyone` (default) xabarni hamma uchun o'chiradi, timeline'da tombstone qoladi.
+//	@Description	Yuboruvchi hamma uchun faqat sozlangan vaqt oralig'ida o'chira oladi; group owner/admin istalgan xabarni o'chira oladi.
 //	@Tags			messages
 //	@Produce		json
 //	@Param			Authorization	header		string				true	"Bearer token: Bearer <token>"
 //	@Param			id				path		int					true	"Xabar ID"
-//	@Success		200				{object}	map[string]any		"{"data":{"result":"deleted"}}"
-//	@Failure		400				{object}	map[string]string	"ID noto'g'ri"
+//	@Param			scope			query		string				false	"me | everyone"	default(everyone)
+//	@Success		200				{object}	map[string]any		"{"data":{"result":"deleted","scope":"everyone"}}"
+//	@Failure		400				{object}	map[string]string	"ID yoki scope noto'g'ri"
 //	@Failure		401				{object}	map[string]string	"Authorization Bearer token yuborilmagan yoki noto'g'ri"
-//	@Failure		404				{object}	map[string]string	"Xabar topilmadi yoki userga tegishli emas"
+//	@Failure		403				{object}	map[string]string	"Ruxsat yo'q yoki o'chirish muddati o'tgan"
+//	@Failure		404				{object}	map[string]string	"Xabar topilmadi"
 //	@Failure		500				{object}	map[string]string	"Ichki server xatosi"
 //	@Router			/messages/{id} [delete]
 func (app *application) MessageDeleteHandler(w http.ResponseWriter, r *http.Request) {
@@ -319,41 +530,24 @@ func (app *application) MessageDeleteHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	msg, err := app.services.MessageSRV.GetByID(r.Context(), msgID)
-	if err != nil {
-		switch {
-		case errors.Is(err, store.SqlNotfound):
-			app.notFoundError(w, r, err)
-		default:
-			app.internalServerError(w, r, err)
-		}
-		return
+	scope := r.URL.Query().Get("scope")
+	if scope == "" {
+		scope = deleteScopeEveryone
 	}
-
-	if err := app.services.MessageSRV.DeleteMessage(r.Context(), msgID, senderID.ID); err != nil {
-		switch {
-		case errors.Is(err, store.SqlNotfound):
-			app.notFoundError(w, r, err)
-		default:
-			app.internalServerError(w, r, err)
-		}
+	if scope != deleteScopeMe && scope != deleteScopeEveryone {
+		app.badRequestError(w, r, errors.New("scope must be one of: me, everyone"))
 		return
 	}
 
-	memberUsers, err := app.services.MemberSRV.GetByChatID(r.Context(), int(msg.ChatID))
-	if err != nil {
-		app.internalServerError(w, r, err)
+	if err := app.deleteMessage(r.Context(), senderID.ID, msgID, scope); err != nil {
+		app.messageOpError(w, r, err)
 		return
 	}
 
-	memberIDs := make([]string, len(memberUsers))
-	for i, user := range memberUsers {
-		memberIDs[i] = strconv.FormatInt(user.ID, 10)
-	}
-
-	go app.ws.BroadcastMessageDelete(msg.ChatID, msgID, memberIDs)
-
-	if err := app.jsonResponse(w, http.StatusOK, map[string]string{"result": "deleted"}); err != nil {
+	if err := app.jsonResponse(w, http.StatusOK, map[string]string{
+		"result": "deleted",
+		"scope":  scope,
+	}); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"chatX/internal/store"
+	service "chatX/internal/usecase"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// Xabar amallari HTTP handler'lar va WebSocket buyruqlari uchun umumiy:
+// a'zolik tekshiruvi, MessageSRV chaqiruvi va outbox relay'ni uyg'otish bir joyda.
+// Event'larning o'zi MessageSRV transaction'ida outbox'ga yoziladi.
+
+var (
+	errNotChatMember        = errors.New("user is not a member of this chat")
+	errScheduledAttachments = errors.New("scheduled messages cannot have attachments")
+)
+
+// requireMember - user chat a'zosi bo'lmasa errNotChatMember qaytaradi
+func (app *application) requireMember(ctx context.Context, chatID, userID int64) error {
+	isMember, err := app.services.MemberSRV.IsMember(ctx, chatID, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return errNotChatMember
+	}
+	return nil
+}
+
+// loadChatMembers - hub'ning chat a'zolari indeksi uchun loader: chat indeksda bo'lmaganda chaqiriladi
+func (app *application) loadChatMembers(ctx context.Context, chatID int64) ([]string, error) {
+	memberUsers, err := app.services.MemberSRV.GetByChatID(ctx, int(chatID))
+	if err != nil {
+		return nil, err
+	}
+
+	memberIDs := make([]string, len(memberUsers))
+	for i, user := range memberUsers {
+		memberIDs[i] = strconv.FormatInt(user.ID, 10)
+	}
+	return memberIDs, nil
+}
+
+func (app *application) createMessage(ctx context.Context, userID int64, req createMessageRequest) (*service.Message, error) {
+	if err := app.requireMember(ctx, req.ChatID, userID); err != nil {
+		return nil, err
+	}
+
+	msg, err := app.services.MessageSRV.Create(ctx, service.Message{
+		ChatID:           req.ChatID,
+		SenderID:         userID,
+		MessageText:      req.MessageText,
+		ReplyToMessageID: req.ReplyToMessageID,
+		AttachmentIDs:    req.AttachmentIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	app.notifyOutbox()
+
+	return msg, nil
+}
+
+func (app *application) scheduleMessage(ctx context.Context, userID int64, req createMessageRequest) (*service.ScheduledMessage, error) {
+	if err := app.requireMember(ctx, req.ChatID, userID); err != nil {
+		return nil, err
+	}
+	if len(req.AttachmentIDs) > 0 {
+		return nil, errScheduledAttachments
+	}
+
+	return app.services.MessageSRV.Schedule(ctx, service.Message{
+		ChatID:           req.ChatID,
+		SenderID:         userID,
+		MessageText:      req.MessageText,
+		ReplyToMessageID: req.ReplyToMessageID,
+	}, *req.SendAt)
+}
+
+func (app *application) updateMessage(ctx context.Context, userID, msgID int64, text string) (string, error) {
+	editedAt, err := app.services.MessageSRV.UpdateMessage(ctx, msgID, userID, text)
+	if err != nil {
+		return "", err
+	}
+
+	app.notifyOutbox()
+
+	return editedAt, nil
+}
+
+func (app *application) deleteMessage(ctx context.Context, userID, msgID int64, scope string) error {
+	msg, err := app.services.MessageSRV.GetByID(ctx, msgID)
+	if err != nil {
+		return err
+	}
+
+	if scope == deleteScopeMe {
+		if err := app.requireMember(ctx, msg.ChatID, userID); err != nil {
+			return err
+		}
+
+		if err := app.services.MessageSRV.HideMessage(ctx, msgID, userID); err != nil {
+			return err
+		}
+
+		app.notifyOutbox()
+		return nil
+	}
+
+	if err := app.services.MessageSRV.DeleteMessage(ctx, msgID, userID, app.config.message.deleteWindow); err != nil {
+		return err
+	}
+
+	app.notifyOutbox()
+
+	return nil
+}
+
+func (app *application) markChatRead(ctx context.Context, userID, chatID int64) (int64, error) {
+	if err := app.requireMember(ctx, chatID, userID); err != nil {
+		return 0, err
+	}
+
+	lastReadID, err := app.services.MessageSRV.MarkChatAsRead(ctx, chatID, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	app.notifyOutbox()
+
+	return lastReadID, nil
+}
+
+// startTyping - a'zolikni tekshirib, chatning boshqa a'zolariga typing_started yuboradi
+func (app *application) startTyping(ctx context.Context, userID, chatID int64) error {
+	if err := app.requireMember(ctx, chatID, userID); err != nil {
+		return err
+	}
+
+	memberIDs, err := app.ws.ChatMembers(ctx, chatID)
+	if err != nil {
+		return err
+	}
+
+	app.ws.StartTyping(chatID, strconv.FormatInt(userID, 10), memberIDs)
+	return nil
+}
+
+// messageOpStatus - xabar amali xatosining HTTP statusi.
+// WebSocket ack'lari ham shu statusdan foydalanadi.
+func messageOpStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidReply),
+		errors.Is(err, service.ErrInvalidAttachment),
+		errors.Is(err, service.ErrSendAtInPast),
+		errors.Is(err, errScheduledAttachments):
+		return http.StatusBadRequest
+	case errors.Is(err, store.SqlNotfound):
+		return http.StatusNotFound
+	case errors.Is(err, errNotChatMember),
+		errors.Is(err, store.SqlForbidden),
+		errors.Is(err, service.ErrDeleteWindowExpired):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (app *application) messageOpError(w http.ResponseWriter, r *http.Request, err error) {
+	switch messageOpStatus(err) {
+	case http.StatusBadRequest:
+		app.badRequestError(w, r, err)
+	case http.StatusNotFound:
+		app.notFoundError(w, r, err)
+	case http.StatusForbidden:
+		app.forbiddenError(w, r, err)
+	default:
+		app.internalServerError(w, r, err)
+	}
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	service "chatX/internal/usecase"
 	"context"
 	"errors"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
@@ -11,8 +13,47 @@ import (
 )
 
 func (app *application) AuthMiddleware(next http.Handler) http.Handler {
+	return app.authenticate(next, false)
+}
+
+// StreamAuthMiddleware - WebSocket va SSE ulanishlari uchun. Browser bu API'larda header yubora olmaydi,
+// shuning uchun `?ticket=` (POST /ws/ticket bergan bir martalik ticket) qabul qilinadi.
+// Ticket bo'lmasa Authorization header, allow_query_token yoqilgan bo'lsa `?token=` ham ishlaydi.
+func (app *application) StreamAuthMiddleware(next http.Handler) http.Handler {
+	withToken := app.authenticate(next, app.config.auth.allowQueryToken)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ticket := strings.TrimSpace(r.URL.Query().Get("ticket"))
+		if ticket == "" {
+			withToken.ServeHTTP(w, r)
+			return
+		}
+
+		userID, err := app.services.TicketSRV.Redeem(r.Context(), ticket, app.clientIP(r))
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidTicket) {
+				app.unauthorizedError(w, r, err)
+				return
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		user, err := app.services.UserSrvc.GetUserByID(r.Context(), userID)
+		if err != nil {
+			app.unauthorizedError(w, r, errors.New("user not found"))
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), "user", user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// authenticate - JWT'ni tekshirib userni context'ga qo'yadi. allowQuery bo'lsa token `?token=` orqali ham olinadi.
+func (app *application) authenticate(next http.Handler, allowQuery bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		tokenString, err := readBearerToken(r)
+		tokenString, err := readBearerToken(r, allowQuery)
 		if err != nil {
 			app.unauthorizedError(w, r, err)
 			return
@@ -53,7 +94,7 @@ func (app *application) AuthMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-func readBearerToken(r *http.Request) (string, error) {
+func readBearerToken(r *http.Request, allowQuery bool) (string, error) {
 	authHeader := strings.TrimSpace(r.Header.Get("Authorization"))
 	if authHeader != "" {
 		parts := strings.SplitN(authHeader, " ", 2)
@@ -68,9 +109,9 @@ func readBearerToken(r *http.Request) (string, error) {
 		return token, nil
 	}
 
-	// Browser WebSocket API custom header yubora olmagani uchun
-	// websocket upgrade requestda query token fallback ishlatiladi.
-	if strings.EqualFold(strings.TrimSpace(r.Header.Get("Upgrade")), "websocket") {
+	// Eski client'lar uchun: query'dagi JWT proxy va access log'larga tushadi, shuning uchun
+	// u faqat WebSocket/SSE route'larida va allow_query_token yoqilganda qabul qilinadi.
+	if allowQuery {
 		token := strings.TrimSpace(r.URL.Query().Get("token"))
 		if token != "" {
 			return token, nil
@@ -80,6 +121,27 @@ func readBearerToken(r *http.Request) (string, error) {
 	return "", errors.New("missing Authorization header")
 }
 
+// clientIP - ticket'ni IP'ga bog'lash uchun client manzili (port'siz).
+// auth.ws_ticket.client_ip_header sozlangan bo'lsa IP shu header'dan olinadi: proxy o'zi ko'rgan manzilni
+// ro'yxat oxiriga qo'shadi, oldingi qiymatlarni esa client o'zi yozgan bo'lishi mumkin,
+// shuning uchun faqat oxirgi qiymatga ishoniladi. Header bo'lmasa ulanish manzili ishlatiladi.
+func (app *application) clientIP(r *http.Request) string {
+	if header := app.config.auth.ticket.clientIPHeader; header != "" {
+		if values := r.Header.Values(header); len(values) > 0 {
+			hops := strings.Split(values[len(values)-1], ",")
+			if ip := net.ParseIP(strings.TrimSpace(hops[len(hops)-1])); ip != nil {
+				return ip.String()
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 func parseSubjectID(subject any) (int64, error) {
 	switch value := subject.(type) {
 	case float64:
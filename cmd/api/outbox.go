@@ -0,0 +1,182 @@
+package main
+
+import (
+	"chatX/internal/store"
+	service "chatX/internal/usecase"
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+const (
+	// outboxPollInterval - notifyOutbox chaqirilmagan hollar uchun (boshqa instance yozgan yoki
+	// relay qulagan bo'lsa) outbox shu oraliqda tekshiriladi
+	outboxPollInterval  = time.Second
+	outboxBatch         = 100
+	outboxPruneInterval = time.Hour
+	outboxPruneBatch    = 1000
+	// outboxRetention - yuborilgan event'lar shuncha vaqt saqlanadi (nima yuborilgani izi)
+	outboxRetention = 72 * time.Hour
+)
+
+// notifyOutbox - yangi outbox event'i commit bo'lgach relay'ni darhol uyg'otadi
+func (app *application) notifyOutbox() {
+	select {
+	case app.outboxNotify <- struct{}{}:
+	default:
+	}
+}
+
+// runOutboxRelay - outbox'dagi event'larni hub'ga yuboradi. Event'lar o'zgarish bilan
+// bitta transaction'da yozilgani uchun server broadcast'dan oldin qulasa ham yo'qolmaydi:
+// qayta ishga tushgach yuboriladi (at-least-once).
+func (app *application) runOutboxRelay(ctx context.Context) {
+	poll := time.NewTicker(outboxPollInterval)
+	defer poll.Stop()
+	prune := time.NewTicker(outboxPruneInterval)
+	defer prune.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-app.outboxNotify:
+			app.relayOutbox(ctx)
+		case <-poll.C:
+			app.relayOutbox(ctx)
+		case <-prune.C:
+			app.pruneOutbox(ctx)
+		}
+	}
+}
+
+func (app *application) relayOutbox(ctx context.Context) {
+	for {
+		relayed, err := app.services.OutboxSRV.Relay(ctx, outboxBatch, app.publishOutboxEntry)
+		if err != nil {
+			app.logger.Errorw("outbox relay failed", "error", err)
+			return
+		}
+		if relayed < outboxBatch {
+			return
+		}
+	}
+}
+
+func (app *application) pruneOutbox(ctx context.Context) {
+	for {
+		deleted, err := app.services.OutboxSRV.Prune(ctx, outboxRetention, outboxPruneBatch)
+		if err != nil {
+			app.logger.Errorw("outbox prune failed", "error", err)
+			return
+		}
+		if deleted < outboxPruneBatch {
+			return
+		}
+	}
+}
+
+// publishOutboxEntry - outbox event'ini tegishli hub broadcast'iga aylantiradi.
+// Broadcast'lar ketma-ket chaqiriladi, shunda client event'larni yozilgan tartibda oladi.
+// Broadcast xatosi qaytariladi: event yuborilgan deb belgilanmaydi va keyinroq qayta yuboriladi.
+// O'qib bo'lmaydigan event esa log qilinadi va o'tkazib yuboriladi: u navbatni to'sib qo'ymasligi kerak.
+func (app *application) publishOutboxEntry(entry store.OutboxEntry) error {
+	switch entry.EventType {
+	case service.OutboxMessageCreated:
+		var msg service.Message
+		if !app.decodeOutboxEntry(entry, &msg) {
+			return nil
+		}
+		return app.broadcastNewMessage(&msg)
+
+	case service.OutboxMessageUpdated:
+		var e service.MessageUpdatedEvent
+		if !app.decodeOutboxEntry(entry, &e) {
+			return nil
+		}
+		return app.ws.BroadcastMessageUpdate(e.ChatID, e.MessageID, e.MessageText, e.EditedAt)
+
+	case service.OutboxMessageDeleted:
+		var e service.MessageDeletedEvent
+		if !app.decodeOutboxEntry(entry, &e) {
+			return nil
+		}
+		return app.ws.BroadcastMessageDelete(e.ChatID, e.MessageID)
+
+	case service.OutboxMessageHidden:
+		var e service.MessageHiddenEvent
+		if !app.decodeOutboxEntry(entry, &e) {
+			return nil
+		}
+		return app.ws.BroadcastMessageHide(e.ChatID, e.MessageID, strconv.FormatInt(e.UserID, 10))
+
+	case service.OutboxMessagesRead:
+		var e service.MessagesReadEvent
+		if !app.decodeOutboxEntry(entry, &e) {
+			return nil
+		}
+		// O'quvchining o'zi ham oladi: boshqa qurilmalaridagi unread hisoblagichlar tozalanadi
+		return app.ws.BroadcastReadStatus(e.ChatID, strconv.FormatInt(e.ReaderID, 10), e.LastReadID)
+
+	case service.OutboxReactionAdded:
+		var e service.ReactionEvent
+		if !app.decodeOutboxEntry(entry, &e) {
+			return nil
+		}
+		return app.ws.BroadcastReactionAdded(e.ChatID, e.MessageID, e.UserID, e.Username, e.Emoji)
+
+	case service.OutboxReactionRemoved:
+		var e service.ReactionEvent
+		if !app.decodeOutboxEntry(entry, &e) {
+			return nil
+		}
+		return app.ws.BroadcastReactionRemoved(e.ChatID, e.MessageID, e.UserID, e.Emoji)
+
+	case service.OutboxMemberAdded:
+		var e service.MemberEvent
+		if !app.decodeOutboxEntry(entry, &e) {
+			return nil
+		}
+		app.ws.AddChatMember(e.ChatID, strconv.FormatInt(e.UserID, 10))
+		return app.ws.BroadcastMemberAdded(e.ChatID, e.UserID, e.ActorID, orDefault(e.Username, "foydalanuvchi"), orDefault(e.ActorName, "Kimdir"))
+
+	case service.OutboxMemberRemoved:
+		var e service.MemberEvent
+		if !app.decodeOutboxEntry(entry, &e) {
+			return nil
+		}
+		app.ws.RemoveChatMember(e.ChatID, strconv.FormatInt(e.UserID, 10))
+		return nil
+
+	case service.OutboxChatDeleted:
+		var e service.ChatDeletedEvent
+		if !app.decodeOutboxEntry(entry, &e) {
+			return nil
+		}
+		memberIDs := make([]string, len(e.MemberIDs))
+		for i, id := range e.MemberIDs {
+			memberIDs[i] = strconv.FormatInt(id, 10)
+		}
+		return app.ws.BroadcastChatDelete(e.ChatID, e.DeletedByID, orDefault(e.DeletedByName, "Kimdir"), memberIDs)
+
+	default:
+		app.logger.Errorw("unknown outbox event type", "id", entry.ID, "event_type", entry.EventType)
+		return nil
+	}
+}
+
+func (app *application) decodeOutboxEntry(entry store.OutboxEntry, dst any) bool {
+	if err := json.Unmarshal(entry.Payload, dst); err != nil {
+		app.logger.Errorw("outbox event decoding failed", "error", err, "id", entry.ID, "event_type", entry.EventType)
+		return false
+	}
+	return true
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
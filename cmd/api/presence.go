@@ -0,0 +1,137 @@
+package main
+
+import (
+	"chatX/internal/store"
+	"chatX/internal/ws"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+type updatePrivacyRequest struct {
+	HideLastSeen *bool `json:"hide_last_seen" validate:"required"`
+}
+
+// memberWithPresence - a'zo ma'lumotlari va uning online holati
+type memberWithPresence struct {
+	store.User
+	Presence store.Presence `json:"presence"`
+}
+
+// runPresenceRecorder - hub'dan kelgan presence o'zgarishlarini qayta ishlaydi:
+// offline bo'lganda last_seen_at yoziladi, umumiy chatdagi userlarga presence_changed yuboriladi
+func (app *application) runPresenceRecorder(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case p := <-app.ws.PresenceChanges:
+			app.recordPresence(ctx, p)
+		}
+	}
+}
+
+func (app *application) recordPresence(ctx context.Context, p ws.PresenceChange) {
+	userID, err := strconv.ParseInt(p.UserID, 10, 64)
+	if err != nil {
+		return
+	}
+
+	var lastSeen *string
+	if p.Status == ws.PresenceOffline {
+		lastSeen, err = app.services.PresenceSRV.MarkOffline(ctx, userID)
+		if err != nil {
+			app.logger.Errorw("last seen update failed", "error", err, "user_id", userID)
+			return
+		}
+	}
+
+	contactIDs, err := app.services.PresenceSRV.GetContactIDs(ctx, userID)
+	if err != nil {
+		app.logger.Errorw("presence contacts lookup failed", "error", err, "user_id", userID)
+		return
+	}
+
+	recipients := make([]string, len(contactIDs))
+	for i, id := range contactIDs {
+		recipients[i] = strconv.FormatInt(id, 10)
+	}
+
+	app.ws.BroadcastPresence(p.UserID, p.Status, lastSeen, recipients)
+}
+
+// withPresence - a'zolar ro'yxatiga hub'dagi holat va bazadagi last_seen_at ni qo'shadi
+func (app *application) withPresence(ctx context.Context, users []store.User) ([]memberWithPresence, error) {
+	ids := make([]int64, len(users))
+	for i, u := range users {
+		ids[i] = u.ID
+	}
+
+	lastSeen, err := app.services.PresenceSRV.GetLastSeen(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]memberWithPresence, len(users))
+	for i, u := range users {
+		result[i] = memberWithPresence{
+			User: u,
+			Presence: store.Presence{
+				Status:     app.ws.Presence(strconv.FormatInt(u.ID, 10)),
+				LastSeenAt: lastSeen[u.ID],
+			},
+		}
+	}
+
+	return result, nil
+}
+
+// UpdatePrivacyHandler godoc
+//
+//	@Summary		Maxfiylik sozlamalari
+//	@Description	Joriy user oxirgi ko'ringan vaqtini (`last_seen_at`) boshqalardan yashirishi mumkin.
+//	@Description	Yashirilganda online/away/offline holati ko'rinadi, lekin `last_seen_at` doim null qaytadi.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token: Bearer <token>"
+//	@Param			payload			body		updatePrivacyRequest	true	"Maxfiylik sozlamalari"
+//	@Success		200				{object}	map[string]any			"{"data":{"hide_last_seen":true}}"
+//	@Failure		400				{object}	map[string]string		"Body noto'g'ri"
+//	@Failure		401				{object}	map[string]string		"Authorization Bearer token yuborilmagan yoki noto'g'ri"
+//	@Failure		404				{object}	map[string]string		"User topilmadi"
+//	@Failure		500				{object}	map[string]string		"Ichki server xatosi"
+//	@Router			/users/privacy [patch]
+func (app *application) UpdatePrivacyHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserfromContext(r)
+	if !ok {
+		app.unauthorizedError(w, r, errors.New("user not found in context"))
+		return
+	}
+
+	var req updatePrivacyRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := app.services.PresenceSRV.SetHideLastSeen(r.Context(), user.ID, *req.HideLastSeen); err != nil {
+		switch {
+		case errors.Is(err, store.SqlNotfound):
+			app.notFoundError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, map[string]bool{"hide_last_seen": *req.HideLastSeen}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"chatX/internal/store"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type reactionRequest struct {
+	Emoji string `json:"emoji" validate:"required,max=32,emoji"`
+}
+
+// AddReactionHandler godoc
+//
+//	@Summary		Xabarga reaksiya qo'shish
+//	@Description	Joriy foydalanuvchi chatdagi xabarga emoji reaksiya qo'shadi. Bir xil reaksiya qayta yuborilsa o'zgarish bo'lmaydi.
+//	@Description	`emoji` bitta emoji bo'lishi kerak (teri rangi, bayroq, keycap va ZWJ zanjirlari ham): matn yoki bir nechta emoji 400 qaytaradi.
+//	@Tags			reactions
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string				true	"Bearer token: Bearer <token>"
+//	@Param			id				path		int					true	"Xabar ID"
+//	@Param			payload			body		reactionRequest		true	"Emoji"
+//	@Success		201				{object}	map[string]any		"{"data":{"result":"added","emoji":"👍"}}"
+//	@Failure		400				{object}	map[string]string	"ID yoki body noto'g'ri"
+//	@Failure		401				{object}	map[string]string	"Authorization Bearer token yuborilmagan yoki noto'g'ri"
+//	@Failure		403				{object}	map[string]string	"User chat a'zosi emas"
+//	@Failure		404				{object}	map[string]string	"Xabar topilmadi"
+//	@Failure		500				{object}	map[string]string	"Ichki server xatosi"
+//	@Router			/messages/{id}/reactions [post]
+func (app *application) AddReactionHandler(w http.ResponseWriter, r *http.Request) {
+	senderID, ok := getUserfromContext(r)
+	if !ok {
+		app.unauthorizedError(w, r, errors.New("user not found in context"))
+		return
+	}
+
+	msgID, err := parsePathInt64(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req reactionRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.Emoji = strings.TrimSpace(req.Emoji)
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	msg, err := app.services.MessageSRV.GetByID(r.Context(), msgID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.SqlNotfound):
+			app.notFoundError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	if msg.IsDeleted {
+		app.notFoundError(w, r, store.SqlNotfound)
+		return
+	}
+
+	isMember, err := app.services.MemberSRV.IsMember(r.Context(), msg.ChatID, senderID.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if !isMember {
+		app.forbiddenError(w, r, errors.New("user is not a member of this chat"))
+		return
+	}
+
+	if err := app.services.MessageSRV.AddReaction(r.Context(), msgID, senderID.ID, req.Emoji); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.notifyOutbox()
+
+	if err := app.jsonResponse(w, http.StatusCreated, map[string]string{
+		"result": "added",
+		"emoji":  req.Emoji,
+	}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// RemoveReactionHandler godoc
+//
+//	@Summary		Xabardan reaksiyani olib tashlash
+//	@Description	Joriy foydalanuvchi o'zi qo'shgan emoji reaksiyani olib tashlaydi. Emoji path ichida URL-encode qilinadi.
+//	@Tags			reactions
+//	@Produce		json
+//	@Param			Authorization	header		string				true	"Bearer token: Bearer <token>"
+//	@Param			id				path		int					true	"Xabar ID"
+//	@Param			emoji			path		string				true	"URL-encode qilingan emoji"
+//	@Success		200				{object}	map[string]any		"{"data":{"result":"removed"}}"
+//	@Failure		400				{object}	map[string]string	"ID yoki emoji noto'g'ri"
+//	@Failure		401				{object}	map[string]string	"Authorization Bearer token yuborilmagan yoki noto'g'ri"
+//	@Failure		403				{object}	map[string]string	"User chat a'zosi emas"
+//	@Failure		404				{object}	map[string]string	"Xabar yoki reaksiya topilmadi"
+//	@Failure		500				{object}	map[string]string	"Ichki server xatosi"
+//	@Router			/messages/{id}/reactions/{emoji} [delete]
+func (app *application) RemoveReactionHandler(w http.ResponseWriter, r *http.Request) {
+	senderID, ok := getUserfromContext(r)
+	if !ok {
+		app.unauthorizedError(w, r, errors.New("user not found in context"))
+		return
+	}
+
+	msgID, err := parsePathInt64(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	emoji, err := url.PathUnescape(chi.URLParam(r, "emoji"))
+	if err != nil || strings.TrimSpace(emoji) == "" {
+		app.badRequestError(w, r, errors.New("emoji is required"))
+		return
+	}
+	if !isEmoji(emoji) {
+		app.badRequestError(w, r, errors.New("emoji must be a single emoji"))
+		return
+	}
+
+	msg, err := app.services.MessageSRV.GetByID(r.Context(), msgID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.SqlNotfound):
+			app.notFoundError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	isMember, err := app.services.MemberSRV.IsMember(r.Context(), msg.ChatID, senderID.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if !isMember {
+		app.forbiddenError(w, r, errors.New("user is not a member of this chat"))
+		return
+	}
+
+	if err := app.services.MessageSRV.RemoveReaction(r.Context(), msgID, senderID.ID, emoji); err != nil {
+		switch {
+		case errors.Is(err, store.SqlNotfound):
+			app.notFoundError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.notifyOutbox()
+
+	if err := app.jsonResponse(w, http.StatusOK, map[string]string{"result": "removed"}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"chatX/internal/store"
+	"chatX/internal/ws"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// deliveryBatchSize - bitta INSERT bilan yoziladigan "yetkazildi" signallari soni
+const deliveryBatchSize = 256
+
+// runDeliveryRecorder - hub'dan kelgan "yetkazildi" signallarini bazaga yozadi
+// va yuboruvchiga message_delivered eventini jo'natadi.
+// Signal kelgach kanalda kutib turganlari ham olinadi va hammasi bitta so'rovda yoziladi.
+func (app *application) runDeliveryRecorder(ctx context.Context) {
+	batch := make([]ws.Delivery, 0, deliveryBatchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d := <-app.ws.Deliveries:
+			batch = append(batch[:0], d)
+		}
+
+	drain:
+		for len(batch) < deliveryBatchSize {
+			select {
+			case d := <-app.ws.Deliveries:
+				batch = append(batch, d)
+			default:
+				break drain
+			}
+		}
+
+		app.recordDeliveries(ctx, batch)
+	}
+}
+
+func (app *application) recordDeliveries(ctx context.Context, batch []ws.Delivery) {
+	signals := make(map[store.Delivery]ws.Delivery, len(batch))
+	deliveries := make([]store.Delivery, 0, len(batch))
+	for _, d := range batch {
+		recipientID, err := strconv.ParseInt(d.RecipientID, 10, 64)
+		if err != nil {
+			continue
+		}
+		key := store.Delivery{MessageID: d.MessageID, UserID: recipientID}
+		// Bir nechta qurilmaga yetkazilgan xabar bitta yozuv bo'ladi
+		if _, ok := signals[key]; ok {
+			continue
+		}
+		signals[key] = d
+		deliveries = append(deliveries, key)
+	}
+
+	created, err := app.services.MessageSRV.MarkDelivered(ctx, deliveries)
+	if err != nil {
+		app.logger.Errorw("message delivery record failed",
+			"error", err,
+			"count", len(deliveries),
+		)
+		return
+	}
+
+	for _, key := range created {
+		d := signals[key]
+		if err := app.ws.BroadcastDelivered(d.ChatID, d.MessageID, d.RecipientID, d.SenderID); err != nil {
+			app.logger.Errorw("message delivered broadcast failed",
+				"error", err,
+				"message_id", d.MessageID,
+				"user_id", key.UserID,
+			)
+		}
+	}
+}
+
+// GetMessageReceiptsHandler godoc
+//
+//	@Summary		Xabar kimga yetkazilgani va kim o'qigani
+//	@Description	Xabar yuboruvchisi uchun: chatning boshqa a'zolari bo'yicha `sent`/`delivered`/`read` holati va vaqtlari.
+//	@Description	`delivered_at` xabar WebSocket client'iga yozilgan vaqt, `read_at` chat o'qilgan deb belgilangan vaqt.
+//	@Tags			messages
+//	@Produce		json
+//	@Param			Authorization	header		string				true	"Bearer token: Bearer <token>"
+//	@Param			id				path		int					true	"Xabar ID"
+//	@Success		200				{object}	map[string]any		"{"data":[{"user_id":2,"username":"ali","status":"read","delivered_at":"...","read_at":"..."}]}"
+//	@Failure		400				{object}	map[string]string	"ID noto'g'ri"
+//	@Failure		401				{object}	map[string]string	"Authorization Bearer token yuborilmagan yoki noto'g'ri"
+//	@Failure		403				{object}	map[string]string	"Faqat xabar yuboruvchisi ko'ra oladi"
+//	@Failure		404				{object}	map[string]string	"Xabar topilmadi"
+//	@Failure		500				{object}	map[string]string	"Ichki server xatosi"
+//	@Router			/messages/{id}/receipts [get]
+func (app *application) GetMessageReceiptsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserfromContext(r)
+	if !ok {
+		app.unauthorizedError(w, r, errors.New("user not found in context"))
+		return
+	}
+
+	msgID, err := parsePathInt64(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	msg, err := app.services.MessageSRV.GetByID(r.Context(), msgID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.SqlNotfound):
+			app.notFoundError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	if msg.SenderID != user.ID {
+		app.forbiddenError(w, r, errors.New("only the sender can view message receipts"))
+		return
+	}
+
+	receipts, err := app.services.MessageSRV.GetReceipts(r.Context(), msgID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, receipts); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"chatX/internal/store"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	scheduledDispatchInterval = 5 * time.Second
+	scheduledDispatchBatch    = 100
+)
+
+// GetScheduledMessagesHandler godoc
+//
+//	@Summary		Rejalashtirilgan xabarlar
+//	@Description	Joriy foydalanuvchining hali yuborilmagan (pending) rejalashtirilgan xabarlarini send_at bo'yicha qaytaradi.
+//	@Tags			messages
+//	@Produce		json
+//	@Param			Authorization	header		string				true	"Bearer token: Bearer <token>"
+//	@Success		200				{object}	map[string]any		"{"data":[...rejalashtirilgan xabarlar...]}"
+//	@Failure		401				{object}	map[string]string	"Authorization Bearer token yuborilmagan yoki noto'g'ri"
+//	@Failure		500				{object}	map[string]string	"Ichki server xatosi"
+//	@Router			/messages/scheduled [get]
+func (app *application) GetScheduledMessagesHandler(w http.ResponseWriter, r *http.Request) {
+	senderID, ok := getUserfromContext(r)
+	if !ok {
+		app.unauthorizedError(w, r, errors.New("user not found in context"))
+		return
+	}
+
+	scheduled, err := app.services.MessageSRV.GetScheduled(r.Context(), senderID.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, scheduled); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// CancelScheduledMessageHandler godoc
+//
+//	@Summary		Rejalashtirilgan xabarni bekor qilish
+//	@Description	Joriy foydalanuvchining hali yuborilmagan rejalashtirilgan xabarini bekor qiladi.
+//	@Tags			messages
+//	@Produce		json
+//	@Param			Authorization	header		string				true	"Bearer token: Bearer <token>"
+//	@Param			id				path		int					true	"Rejalashtirilgan xabar ID"
+//	@Success		200				{object}	map[string]any		"{"data":{"result":"cancelled"}}"
+//	@Failure		400				{object}	map[string]string	"ID noto'g'ri"
+//	@Failure		401				{object}	map[string]string	"Authorization Bearer token yuborilmagan yoki noto'g'ri"
+//	@Failure		404				{object}	map[string]string	"Xabar topilmadi yoki allaqachon yuborilgan"
+//	@Failure		500				{object}	map[string]string	"Ichki server xatosi"
+//	@Router			/messages/scheduled/{id} [delete]
+func (app *application) CancelScheduledMessageHandler(w http.ResponseWriter, r *http.Request) {
+	senderID, ok := getUserfromContext(r)
+	if !ok {
+		app.unauthorizedError(w, r, errors.New("user not found in context"))
+		return
+	}
+
+	id, err := parsePathInt64(chi.URLParam(r, "id"), "id")
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := app.services.MessageSRV.CancelScheduled(r.Context(), id, senderID.ID); err != nil {
+		switch {
+		case errors.Is(err, store.SqlNotfound):
+			app.notFoundError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, map[string]string{"result": "cancelled"}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// runScheduledDispatcher - vaqti kelgan rejalashtirilgan xabarlarni davriy ravishda yuboradi
+func (app *application) runScheduledDispatcher(ctx context.Context) {
+	ticker := time.NewTicker(scheduledDispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			app.dispatchScheduledMessages(ctx)
+		}
+	}
+}
+
+func (app *application) dispatchScheduledMessages(ctx context.Context) {
+	for {
+		sent, err := app.services.MessageSRV.DispatchScheduled(ctx, scheduledDispatchBatch)
+		if err != nil {
+			app.logger.Errorw("scheduled message dispatch failed",
+				"error", err,
+			)
+			return
+		}
+
+		if len(sent) > 0 {
+			app.notifyOutbox()
+		}
+
+		if len(sent) < scheduledDispatchBatch {
+			return
+		}
+	}
+}
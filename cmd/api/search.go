@@ -0,0 +1,67 @@
+package main
+
+import (
+	"chatX/internal/store"
+	"errors"
+	"net/http"
+)
+
+// SearchMessagesHandler godoc
+//
+//	@Summary		Xabarlarni qidirish
+//	@Description	Joriy user a'zo bo'lgan barcha chatlardagi xabarlarni full-text qidiradi.
+//	@Description	`q` websearch sintaksisini qo'llaydi (`"aniq ibora"`, `-istisno`, `or`).
+//	@Description	Natijalar relevance bo'yicha tartiblanadi, `snippet` HTML-escape qilingan, topilgan so'zlar `<mark>` bilan belgilanadi.
+//	@Description	Keyingi sahifa uchun `next_cursor` qiymati `cursor` sifatida yuboriladi. `context_url` xabar atrofidagi tarixni ochadi.
+//	@Tags			messages
+//	@Produce		json
+//	@Param			Authorization	header		string				true	"Bearer token: Bearer <token>"
+//	@Param			q				query		string				true	"Qidiruv so'rovi"
+//	@Param			chat_id			query		int					false	"Faqat shu chat ichida"
+//	@Param			sender_id		query		int					false	"Faqat shu user yuborgan xabarlar"
+//	@Param			from			query		string				false	"Boshlanish vaqti (RFC3339, shu vaqt ham kiradi)"
+//	@Param			to				query		string				false	"Tugash vaqti (RFC3339, shu vaqt kirmaydi)"
+//	@Param			limit			query		int					false	"Natijalar soni (1..50)"	default(20)
+//	@Param			cursor			query		string				false	"Oldingi javobdagi next_cursor"
+//	@Success		200				{object}	map[string]any		"{"data":{"results":[...],"next_cursor":"..."}}"
+//	@Failure		400				{object}	map[string]string	"Query param noto'g'ri"
+//	@Failure		401				{object}	map[string]string	"Authorization Bearer token yuborilmagan yoki noto'g'ri"
+//	@Failure		500				{object}	map[string]string	"Ichki server xatosi"
+//	@Router			/messages/search [get]
+func (app *application) SearchMessagesHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserfromContext(r)
+	if !ok {
+		app.unauthorizedError(w, r, errors.New("user not found in context"))
+		return
+	}
+
+	sq := store.SearchQuery{
+		Limit: 20,
+	}
+
+	query, err := sq.Parse(r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(query); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	page, err := app.services.MessageSRV.SearchMessages(r.Context(), user.ID, query)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrInvalidCursor):
+			app.badRequestError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, page); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
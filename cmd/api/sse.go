@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"chatX/internal/ws"
+)
+
+// sseRetry - brauzer EventSource uzilganda shuncha kutib qayta ulanadi
+const sseRetry = 3 * time.Second
+
+// EventStreamHandler godoc
+//
+//	@Summary		Realtime event'lar (SSE)
+//	@Description	WebSocket ishlamaydigan tarmoqlar uchun zaxira: WebSocket bilan bir xil event payload'larini
+//	@Description	`text/event-stream` sifatida yuboradi. `seq`li event'lar `id:` bilan keladi; `Last-Event-ID` header'i
+//	@Description	(yoki `last_event_id` query) berilsa shu seq'dan keyingi event'lar avval qayta yuboriladi.
+//	@Description	Kerakli event'lar o'chirilgan bo'lsa `{"type":"resync_required","last_seq":N}` keladi: client
+//	@Description	chatlar va xabarlarni qayta yuklashi kerak. Buyruqlar (typing, presence) SSE orqali yuborilmaydi.
+//	@Description	Brauzer EventSource header yubora olmagani uchun `POST /ws/ticket` bergan bir martalik `?ticket=` ishlatiladi.
+//	@Description	`auth.allow_query_token` yoqilgan bo'lsa JWT `?token=` orqali ham qabul qilinadi.
+//	@Tags			sync
+//	@Produce		text/event-stream
+//	@Param			Authorization	header		string				false	"Bearer token: Bearer <token>"
+//	@Param			ticket			query		string				false	"Bir martalik ulanish ticket'i (EventSource uchun)"
+//	@Param			token			query		string				false	"JWT token (allow_query_token yoqilgan bo'lsa)"
+//	@Param			Last-Event-ID	header		int					false	"Client ko'rgan oxirgi seq"
+//	@Param			last_event_id	query		int					false	"Client ko'rgan oxirgi seq (birinchi ulanish uchun)"
+//	@Success		200				{string}	string				"Event oqimi"
+//	@Failure		400				{object}	map[string]string	"Last-Event-ID noto'g'ri"
+//	@Failure		401				{object}	map[string]string	"Token yuborilmagan yoki noto'g'ri"
+//	@Failure		500				{object}	map[string]string	"Ichki server xatosi"
+//	@Router			/events [get]
+func (app *application) EventStreamHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserfromContext(r)
+	if !ok {
+		app.unauthorizedError(w, r, errors.New("user not found in context"))
+		return
+	}
+
+	lastSeq, resume, err := readLastEventID(r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	// Server WriteTimeout o'rniga har bir yozuvga alohida deadline qo'yiladi,
+	// aks holda uzoq yashaydigan oqim 10 soniyada uzilib qoladi
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Now().Add(app.ws.Config.WriteTimeout)); err != nil {
+		app.internalServerError(w, r, fmt.Errorf("streaming not supported: %w", err))
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds())
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	client := ws.NewClient(app.ws, strconv.FormatInt(user.ID, 10), nil)
+
+	// Avval ro'yxatdan o'tiladi: qayta yuborish paytida kelgan jonli event'lar Send'da kutib turadi
+	app.ws.Register <- client
+	defer func() {
+		app.ws.Unregister <- client
+	}()
+
+	if resume {
+		lastSeq, err = app.replayEvents(r.Context(), w, rc, user.ID, lastSeq)
+		if err != nil {
+			if r.Context().Err() == nil {
+				app.logger.Errorw("event stream replay failed", "error", err, "user_id", user.ID)
+			}
+			return
+		}
+	}
+
+	client.SSEPump(r.Context(), w, rc, lastSeq)
+}
+
+// readLastEventID - brauzer qayta ulanganda Last-Event-ID header'ini o'zi yuboradi,
+// birinchi ulanishda esa client uni query orqali beradi
+func readLastEventID(r *http.Request) (int64, bool, error) {
+	raw := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if raw == "" {
+		raw = strings.TrimSpace(r.URL.Query().Get("last_event_id"))
+	}
+	if raw == "" {
+		return 0, false, nil
+	}
+
+	seq, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || seq < 0 {
+		return 0, false, errors.New("invalid Last-Event-ID: must be a non-negative integer")
+	}
+	return seq, true, nil
+}
+
+// replayEvents - since'dan keyingi saqlangan event'larni oqimga yozadi va oxirgi yuborilgan seq'ni qaytaradi.
+// Event'lar o'chirilgan bo'lsa ular o'rniga resync_required yuboriladi.
+func (app *application) replayEvents(ctx context.Context, w http.ResponseWriter, rc *http.ResponseController, userID, since int64) (int64, error) {
+	for {
+		page, err := app.services.EventSRV.Sync(ctx, userID, since, syncMaxLimit)
+		if err != nil {
+			return since, err
+		}
+		if err := rc.SetWriteDeadline(time.Now().Add(app.ws.Config.WriteTimeout)); err != nil {
+			return since, err
+		}
+
+		if page.Truncated {
+			data := fmt.Appendf(nil, `{"type":"resync_required","last_seq":%d}`, page.LastSeq)
+			// id berilgani uchun keyingi qayta ulanish shu seq'dan davom etadi
+			if err := ws.WriteSSE(w, page.LastSeq, data); err != nil {
+				return since, err
+			}
+			return page.LastSeq, rc.Flush()
+		}
+
+		for _, e := range page.Events {
+			if err := ws.WriteSSE(w, e.Seq, ws.WithSeq(e.Payload, e.Seq)); err != nil {
+				return since, err
+			}
+			since = e.Seq
+		}
+		if err := rc.Flush(); err != nil {
+			return since, err
+		}
+
+		if !page.HasMore {
+			return since, nil
+		}
+	}
+}
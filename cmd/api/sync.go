@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	service "chatX/internal/usecase"
+	"chatX/internal/ws"
+)
+
+const (
+	syncDefaultLimit = 100
+	syncMaxLimit     = 500
+	// resumeLimit - WS resume bitta buyruqda yuboradigan event'lar soni (Send buferidan kichik)
+	resumeLimit = 100
+
+	eventPruneInterval = time.Hour
+	eventPruneBatch    = 1000
+)
+
+type resumeCommand struct {
+	Since int64 `json:"since" validate:"gte=0"`
+}
+
+type syncResponse struct {
+	Events    []json.RawMessage `json:"events"`
+	LastSeq   int64             `json:"last_seq"`
+	HasMore   bool              `json:"has_more"`
+	Truncated bool              `json:"truncated"`
+}
+
+// toSyncResponse - saqlangan event'larni WS frame'lari bilan bir xil ko'rinishga (seq bilan) keltiradi
+func toSyncResponse(page *service.EventPage) syncResponse {
+	resp := syncResponse{
+		Events:    make([]json.RawMessage, len(page.Events)),
+		LastSeq:   page.LastSeq,
+		HasMore:   page.HasMore,
+		Truncated: page.Truncated,
+	}
+	for i, e := range page.Events {
+		resp.Events[i] = ws.WithSeq(e.Payload, e.Seq)
+	}
+	return resp
+}
+
+// SyncHandler godoc
+//
+//	@Summary		O'tkazib yuborilgan event'lar
+//	@Description	Joriy user event oqimidan `since` seq'dan keyingi event'larni tartib bilan qaytaradi.
+//	@Description	Har bir event WebSocket orqali kelgan frame bilan bir xil va `seq` maydoniga ega.
+//	@Description	`has_more` bo'lsa oxirgi event seq'i bilan qayta so'raladi. `truncated` bo'lsa kerakli event'lar
+//	@Description	saqlanish muddati o'tib o'chirilgan: client chatlar va xabarlarni to'liq qayta yuklashi kerak.
+//	@Tags			sync
+//	@Produce		json
+//	@Param			Authorization	header		string				true	"Bearer token: Bearer <token>"
+//	@Param			since			query		int					false	"Client ko'rgan oxirgi seq"	default(0)
+//	@Param			limit			query		int					false	"Event'lar soni (1..500)"	default(100)
+//	@Success		200				{object}	map[string]any		"{"data":{"events":[...],"last_seq":42,"has_more":false,"truncated":false}}"
+//	@Failure		400				{object}	map[string]string	"Query param noto'g'ri"
+//	@Failure		401				{object}	map[string]string	"Authorization Bearer token yuborilmagan yoki noto'g'ri"
+//	@Failure		500				{object}	map[string]string	"Ichki server xatosi"
+//	@Router			/sync [get]
+func (app *application) SyncHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserfromContext(r)
+	if !ok {
+		app.unauthorizedError(w, r, errors.New("user not found in context"))
+		return
+	}
+
+	var since int64
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed < 0 {
+			app.badRequestError(w, r, errors.New("since must be a non-negative integer"))
+			return
+		}
+		since = parsed
+	}
+
+	limit := syncDefaultLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > syncMaxLimit {
+			app.badRequestError(w, r, errors.New("limit must be between 1 and 500"))
+			return
+		}
+		limit = parsed
+	}
+
+	page, err := app.services.EventSRV.Sync(r.Context(), user.ID, since, limit)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, toSyncResponse(page)); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// resumeEvents - WS resume buyrug'i: o'tkazib yuborilgan event'larni shu ulanishga qayta yuboradi.
+// Event'lar ack'dan oldin keladi; jonli event'lar bilan takrorlanishi mumkin, client seq bo'yicha tashlab yuboradi.
+func (app *application) resumeEvents(ctx context.Context, c *ws.Client, userID, since int64) (any, error) {
+	page, err := app.services.EventSRV.Sync(ctx, userID, since, resumeLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range page.Events {
+		if !app.ws.SendTo(c, ws.WithSeq(e.Payload, e.Seq)) {
+			// Bufer to'ldi: client qolganini keyingi resume yoki /sync bilan oladi
+			page.HasMore = true
+			break
+		}
+	}
+
+	return map[string]any{
+		"last_seq":  page.LastSeq,
+		"has_more":  page.HasMore,
+		"truncated": page.Truncated,
+	}, nil
+}
+
+// runEventPruner - saqlanish muddati o'tgan event'larni o'chiradi
+func (app *application) runEventPruner(ctx context.Context) {
+	if app.config.message.eventRetention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(eventPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			app.pruneEvents(ctx)
+		}
+	}
+}
+
+func (app *application) pruneEvents(ctx context.Context) {
+	for {
+		deleted, err := app.services.EventSRV.Prune(ctx, app.config.message.eventRetention, eventPruneBatch)
+		if err != nil {
+			app.logger.Errorw("event log prune failed", "error", err)
+			return
+		}
+		if deleted < eventPruneBatch {
+			return
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+)
+
+// IssueWSTicketHandler godoc
+//
+//	@Summary		WebSocket/SSE ulanish ticket'i
+//	@Description	`GET /ws` va `GET /events` uchun bir martalik, qisqa muddatli ticket beradi: `?ticket=<ticket>`.
+//	@Description	Shunda uzoq yashaydigan JWT URL'ga (proxy va access log'larga) tushmaydi.
+//	@Description	Ticket bir marta ishlatiladi va `auth.ws_ticket.ttl` (default 30s) ichida amal qiladi.
+//	@Description	`auth.ws_ticket.bind_ip` yoqilgan bo'lsa faqat so'ragan IP'dan ishlatiladi
+//	@Description	(proxy ortida IP `auth.ws_ticket.client_ip_header` header'idan olinadi).
+//	@Tags			sync
+//	@Produce		json
+//	@Param			Authorization	header		string				true	"Bearer token: Bearer <token>"
+//	@Success		201				{object}	service.IssuedTicket
+//	@Failure		401				{object}	map[string]string	"Token yuborilmagan yoki noto'g'ri"
+//	@Failure		500				{object}	map[string]string	"Ichki server xatosi"
+//	@Router			/ws/ticket [post]
+func (app *application) IssueWSTicketHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getUserfromContext(r)
+	if !ok {
+		app.unauthorizedError(w, r, errors.New("user not found in context"))
+		return
+	}
+
+	var ip string
+	if app.config.auth.ticket.bindIP {
+		ip = app.clientIP(r)
+	}
+
+	ticket, err := app.services.TicketSRV.Issue(r.Context(), user.ID, ip, app.config.auth.ticket.ttl)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusCreated, ticket); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -71,9 +71,9 @@ func (app *application) GetUserHandler(w http.ResponseWriter, r *http.Request) {
 //	@Tags			users
 //	@Produce		json
 //	@Param			token	path		string				true							"Emailga yuborilgan activation token"
-//	@Success		200		{object}	map[string]any		"{"data":{"message":"account	activated	successfully"}}"
-//	@Failure		400		{object}	map[string]string	"{"error":"activation			token		is	required	|	Not	found"}"
-//	@Failure		500		{object}	map[string]string	"{"error":"internal				server		error"}"
+//	@Success		200		{object}	map[string]any		"{"data":{"message":"account activated successfully"}}"
+//	@Failure		400		{object}	map[string]string	"{"error":"activation token is required | Not found"}"
+//	@Failure		500		{object}	map[string]string	"{"error":"internal server error"}"
 //	@Router			/users/activate/{token} [get]
 //	@Router			/users/activate/{token} [put]
 func (app *application) activateUserHandler(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"chatX/internal/ws"
+)
+
+// WebSocket buyruq protokoli. Client har bir buyruqni konvertda yuboradi:
+//
+//	{"v":1,"id":"c-17","type":"send_message","data":{...}}
+//
+// "id" berilgan bo'lsa server shu ulanishga "ack" (natija bilan) yoki "error" qaytaradi.
+// Buyruqlar xabar amallari uchun HTTP handler'lar bilan bir xil tekshiruvlardan o'tadi.
+const (
+	protocolVersion = 1
+
+	// commandTimeout - bitta buyruqni bajarish uchun vaqt
+	commandTimeout = 10 * time.Second
+
+	// Buyruqlar uchun rate limit: 10 ta burst, keyin har 500ms da bittadan
+	commandRateBurst    = 10
+	commandRateInterval = 500 * time.Millisecond
+)
+
+const (
+	cmdSendMessage   = "send_message"
+	cmdEditMessage   = "edit_message"
+	cmdDeleteMessage = "delete_message"
+	cmdMarkRead      = "mark_read"
+	cmdTypingStarted = "typing_started"
+	cmdTypingStopped = "typing_stopped"
+	cmdSetPresence   = "set_presence"
+	cmdResume        = "resume"
+)
+
+const (
+	wsErrBadRequest         = "bad_request"
+	wsErrForbidden          = "forbidden"
+	wsErrNotFound           = "not_found"
+	wsErrInternal           = "internal"
+	wsErrRateLimited        = "rate_limited"
+	wsErrUnsupportedVersion = "unsupported_version"
+	wsErrUnknownCommand     = "unknown_command"
+)
+
+type wsCommand struct {
+	V    int             `json:"v"`
+	ID   string          `json:"id"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+type wsAck struct {
+	Type   string `json:"type"`
+	V      int    `json:"v"`
+	ID     string `json:"id"`
+	Result any    `json:"result"`
+}
+
+type wsErrorReply struct {
+	Type  string       `json:"type"`
+	V     int          `json:"v"`
+	ID    string       `json:"id"`
+	Error wsErrorField `json:"error"`
+}
+
+type wsErrorField struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// wsCommandError - client'ga qaytariladigan xato (kod va xabar)
+type wsCommandError struct {
+	code    string
+	message string
+}
+
+func (e *wsCommandError) Error() string {
+	return e.message
+}
+
+type editMessageCommand struct {
+	MessageID   int64  `json:"message_id" validate:"required,gt=0"`
+	MessageText string `json:"message_text" validate:"required,max=4000"`
+}
+
+type deleteMessageCommand struct {
+	MessageID int64  `json:"message_id" validate:"required,gt=0"`
+	Scope     string `json:"scope" validate:"omitempty,oneof=me everyone"`
+}
+
+type chatCommand struct {
+	ChatID int64 `json:"chat_id" validate:"required,gt=0"`
+}
+
+type setPresenceCommand struct {
+	Status string `json:"status" validate:"required,oneof=online away"`
+}
+
+// handleClientFrame - WS orqali kelgan buyruqni bajaradi va kerak bo'lsa javob yuboradi
+func (app *application) handleClientFrame(c *ws.Client, data []byte) {
+	var cmd wsCommand
+	if err := json.Unmarshal(data, &cmd); err != nil {
+		app.replyError(c, "", &wsCommandError{code: wsErrBadRequest, message: "frame must be a JSON command"})
+		return
+	}
+
+	if c.Limiter != nil && !c.Limiter.Allow() {
+		app.replyError(c, cmd.ID, &wsCommandError{code: wsErrRateLimited, message: "too many commands"})
+		return
+	}
+
+	if cmd.V != protocolVersion {
+		app.replyError(c, cmd.ID, &wsCommandError{
+			code:    wsErrUnsupportedVersion,
+			message: "supported protocol version is " + strconv.Itoa(protocolVersion),
+		})
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.ID, 10, 64)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	result, err := app.runCommand(ctx, c, userID, cmd)
+	if err != nil {
+		app.replyError(c, cmd.ID, err)
+		return
+	}
+
+	if cmd.ID == "" {
+		return
+	}
+	if result == nil {
+		result = struct{}{}
+	}
+	app.reply(c, wsAck{Type: "ack", V: protocolVersion, ID: cmd.ID, Result: result})
+}
+
+func (app *application) runCommand(ctx context.Context, c *ws.Client, userID int64, cmd wsCommand) (any, error) {
+	switch cmd.Type {
+	case cmdSendMessage:
+		var req createMessageRequest
+		if err := decodeCommand(cmd.Data, &req); err != nil {
+			return nil, err
+		}
+		if req.SendAt != nil {
+			scheduled, err := app.scheduleMessage(ctx, userID, req)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"scheduled_message": scheduled}, nil
+		}
+
+		msg, err := app.createMessage(ctx, userID, req)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"message": msg}, nil
+
+	case cmdEditMessage:
+		var req editMessageCommand
+		if err := decodeCommand(cmd.Data, &req); err != nil {
+			return nil, err
+		}
+
+		editedAt, err := app.updateMessage(ctx, userID, req.MessageID, req.MessageText)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"edited_at": editedAt}, nil
+
+	case cmdDeleteMessage:
+		var req deleteMessageCommand
+		if err := decodeCommand(cmd.Data, &req); err != nil {
+			return nil, err
+		}
+		if req.Scope == "" {
+			req.Scope = deleteScopeEveryone
+		}
+
+		if err := app.deleteMessage(ctx, userID, req.MessageID, req.Scope); err != nil {
+			return nil, err
+		}
+		return map[string]string{"result": "deleted", "scope": req.Scope}, nil
+
+	case cmdMarkRead:
+		var req chatCommand
+		if err := decodeCommand(cmd.Data, &req); err != nil {
+			return nil, err
+		}
+
+		lastReadID, err := app.markChatRead(ctx, userID, req.ChatID)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]int64{"last_read_message_id": lastReadID}, nil
+
+	case cmdTypingStarted, cmdTypingStopped:
+		var req chatCommand
+		if err := decodeCommand(cmd.Data, &req); err != nil {
+			return nil, err
+		}
+
+		if cmd.Type == cmdTypingStopped {
+			app.ws.StopTyping(req.ChatID, c.ID)
+			return nil, nil
+		}
+		return nil, app.startTyping(ctx, userID, req.ChatID)
+
+	case cmdSetPresence:
+		var req setPresenceCommand
+		if err := decodeCommand(cmd.Data, &req); err != nil {
+			return nil, err
+		}
+
+		app.ws.SetAway(c, req.Status == ws.PresenceAway)
+		return nil, nil
+
+	case cmdResume:
+		var req resumeCommand
+		if err := decodeCommand(cmd.Data, &req); err != nil {
+			return nil, err
+		}
+
+		return app.resumeEvents(ctx, c, userID, req.Since)
+
+	default:
+		return nil, &wsCommandError{code: wsErrUnknownCommand, message: "unknown command type: " + cmd.Type}
+	}
+}
+
+// decodeCommand - buyruq ma'lumotini HTTP body kabi qat'iy o'qiydi va validatsiya qiladi
+func decodeCommand(raw json.RawMessage, dst any) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		return &wsCommandError{code: wsErrBadRequest, message: "invalid command data: " + err.Error()}
+	}
+	if err := Validate.Struct(dst); err != nil {
+		return &wsCommandError{code: wsErrBadRequest, message: err.Error()}
+	}
+	return nil
+}
+
+func (app *application) replyError(c *ws.Client, id string, err error) {
+	var cmdErr *wsCommandError
+	if !errors.As(err, &cmdErr) {
+		cmdErr = &wsCommandError{message: err.Error()}
+		switch messageOpStatus(err) {
+		case http.StatusBadRequest:
+			cmdErr.code = wsErrBadRequest
+		case http.StatusNotFound:
+			cmdErr.code = wsErrNotFound
+			cmdErr.message = "not found"
+		case http.StatusForbidden:
+			cmdErr.code = wsErrForbidden
+		default:
+			app.logger.Errorw("websocket command failed", "error", err, "user_id", c.ID)
+			cmdErr.code = wsErrInternal
+			cmdErr.message = "internal server error"
+		}
+	}
+
+	app.reply(c, wsErrorReply{
+		Type:  "error",
+		V:     protocolVersion,
+		ID:    id,
+		Error: wsErrorField{Code: cmdErr.code, Message: cmdErr.message},
+	})
+}
+
+func (app *application) reply(c *ws.Client, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		app.logger.Errorw("websocket reply encoding failed", "error", err)
+		return
+	}
+
+	app.ws.SendTo(c, data)
+}
@@ -0,0 +1,4 @@
+ALTER TABLE messages
+  ADD COLUMN reply_to_message_id BIGINT REFERENCES messages(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_messages_reply_to_message_id ON messages(reply_to_message_id);
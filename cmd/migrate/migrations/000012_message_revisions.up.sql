@@ -0,0 +1,10 @@
+ALTER TABLE messages ADD COLUMN edited_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS message_revisions (
+  id BIGSERIAL PRIMARY KEY,
+  message_id BIGINT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+  message_text TEXT NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_revisions_message_id ON message_revisions(message_id);
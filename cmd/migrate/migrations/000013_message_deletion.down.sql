@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS message_hidden;
+
+ALTER TABLE messages
+  DROP COLUMN deleted_by,
+  DROP COLUMN deleted_at;
@@ -0,0 +1,12 @@
+ALTER TABLE messages
+  ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE,
+  ADD COLUMN deleted_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS message_hidden (
+  message_id BIGINT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  hidden_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (message_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_hidden_user_id ON message_hidden(user_id);
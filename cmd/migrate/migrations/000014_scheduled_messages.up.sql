@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS scheduled_messages (
+  id BIGSERIAL PRIMARY KEY,
+  chat_id BIGINT NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+  sender_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  message_text TEXT NOT NULL,
+  reply_to_message_id BIGINT REFERENCES messages(id) ON DELETE SET NULL,
+  send_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'pending', -- "pending" | "sent" | "cancelled" | "failed"
+  message_id BIGINT REFERENCES messages(id) ON DELETE SET NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  processed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_messages_pending ON scheduled_messages(send_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_scheduled_messages_sender_id ON scheduled_messages(sender_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_messages_expires_at;
+
+ALTER TABLE messages DROP COLUMN expires_at;
+
+ALTER TABLE chats DROP COLUMN message_ttl_seconds;
@@ -0,0 +1,5 @@
+ALTER TABLE chats ADD COLUMN message_ttl_seconds INTEGER;
+
+ALTER TABLE messages ADD COLUMN expires_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_messages_expires_at ON messages(expires_at) WHERE expires_at IS NOT NULL;
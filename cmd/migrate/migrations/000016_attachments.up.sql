@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS attachments (
+  id BIGSERIAL PRIMARY KEY,
+  chat_id BIGINT NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+  uploader_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  message_id BIGINT REFERENCES messages(id) ON DELETE CASCADE,
+  storage_key TEXT NOT NULL UNIQUE,
+  file_name VARCHAR(255) NOT NULL,
+  mime_type VARCHAR(255) NOT NULL,
+  size_bytes BIGINT NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_message_id ON attachments(message_id);
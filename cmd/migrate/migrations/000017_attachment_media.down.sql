@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS attachment_thumbnails;
+
+ALTER TABLE attachments
+  DROP COLUMN IF EXISTS placeholder,
+  DROP COLUMN IF EXISTS height,
+  DROP COLUMN IF EXISTS width;
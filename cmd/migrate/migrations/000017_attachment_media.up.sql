@@ -0,0 +1,13 @@
+ALTER TABLE attachments
+  ADD COLUMN IF NOT EXISTS width INT,
+  ADD COLUMN IF NOT EXISTS height INT,
+  ADD COLUMN IF NOT EXISTS placeholder VARCHAR(64);
+
+CREATE TABLE IF NOT EXISTS attachment_thumbnails (
+  attachment_id BIGINT NOT NULL REFERENCES attachments(id) ON DELETE CASCADE,
+  size VARCHAR(16) NOT NULL,
+  storage_key TEXT NOT NULL UNIQUE,
+  width INT NOT NULL,
+  height INT NOT NULL,
+  PRIMARY KEY (attachment_id, size)
+);
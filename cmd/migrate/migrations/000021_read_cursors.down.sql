@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS message_reads (
+  message_id BIGINT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  read_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (message_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_reads_user_id ON message_reads(user_id);
+
+INSERT INTO message_reads (message_id, user_id, read_at)
+SELECT m.id, cm.user_id, COALESCE(cm.last_read_at, NOW())
+FROM chat_members cm
+JOIN messages m ON m.chat_id = cm.chat_id
+WHERE m.id <= cm.last_read_message_id
+  AND m.sender_id <> cm.user_id
+ON CONFLICT (message_id, user_id) DO NOTHING;
+
+DROP INDEX IF EXISTS idx_messages_chat_id_id;
+DROP TABLE IF EXISTS read_cursor_history;
+
+ALTER TABLE chat_members
+  DROP COLUMN IF EXISTS last_read_at,
+  DROP COLUMN IF EXISTS last_read_message_id;
@@ -0,0 +1,34 @@
+ALTER TABLE chat_members
+  ADD COLUMN IF NOT EXISTS last_read_message_id BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS last_read_at TIMESTAMP WITH TIME ZONE;
+
+-- Har bir "o'qildi" chaqiruvi uchun bitta yozuv: receipts endpoint'i xabar
+-- qachon o'qilganini shu tarixdan aniqlaydi (har xabar uchun alohida qator emas)
+CREATE TABLE IF NOT EXISTS read_cursor_history (
+  id BIGSERIAL PRIMARY KEY,
+  chat_id BIGINT NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  last_read_message_id BIGINT NOT NULL,
+  read_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_read_cursor_history_member ON read_cursor_history(chat_id, user_id, last_read_message_id);
+CREATE INDEX IF NOT EXISTS idx_messages_chat_id_id ON messages(chat_id, id);
+
+INSERT INTO read_cursor_history (chat_id, user_id, last_read_message_id, read_at)
+SELECT m.chat_id, mr.user_id, MAX(mr.message_id), mr.read_at
+FROM message_reads mr
+JOIN messages m ON m.id = mr.message_id
+GROUP BY m.chat_id, mr.user_id, mr.read_at;
+
+UPDATE chat_members cm
+SET last_read_message_id = r.last_read_message_id,
+    last_read_at = r.read_at
+FROM (
+  SELECT chat_id, user_id, MAX(last_read_message_id) AS last_read_message_id, MAX(read_at) AS read_at
+  FROM read_cursor_history
+  GROUP BY chat_id, user_id
+) r
+WHERE cm.chat_id = r.chat_id AND cm.user_id = r.user_id;
+
+DROP TABLE IF EXISTS message_reads;
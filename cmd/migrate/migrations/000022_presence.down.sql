@@ -0,0 +1,3 @@
+ALTER TABLE users
+  DROP COLUMN IF EXISTS hide_last_seen,
+  DROP COLUMN IF EXISTS last_seen_at;
@@ -0,0 +1,17 @@
+-- Har bir user uchun oxirgi berilgan event seq raqami
+CREATE TABLE IF NOT EXISTS user_event_seqs (
+  user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  last_seq BIGINT NOT NULL
+);
+
+-- User'ga yuborilgan muhim WebSocket event'lari: qayta ulanganda seq bo'yicha qayta yuboriladi
+CREATE TABLE IF NOT EXISTS user_events (
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  seq BIGINT NOT NULL,
+  event_type TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (user_id, seq)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_events_created_at ON user_events(created_at);
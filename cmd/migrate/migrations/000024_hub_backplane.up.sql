@@ -0,0 +1,9 @@
+-- NOTIFY payload chegarasidan (8000 bayt) katta backplane xabarlari shu yerda saqlanadi,
+-- NOTIFY orqali esa faqat ularning ID'si yuboriladi. Eski yozuvlarni backplane o'zi tozalaydi.
+CREATE TABLE IF NOT EXISTS hub_backplane_messages (
+  id BIGSERIAL PRIMARY KEY,
+  payload TEXT NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_hub_backplane_messages_created_at ON hub_backplane_messages(created_at);
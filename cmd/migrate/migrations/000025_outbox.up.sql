@@ -0,0 +1,15 @@
+-- Realtime event'lar uchun transactional outbox: event o'zgarish bilan bitta transaction'da yoziladi,
+-- relay uni hub'ga yuborgach published_at belgilanadi.
+-- Relay batch'ni transaction'dan tashqarida yuboradi: claimed_until'gacha batch boshqa relay'ga berilmaydi,
+-- relay qulasa lease tugagach event'lar qayta olinadi.
+CREATE TABLE IF NOT EXISTS outbox_events (
+  id BIGSERIAL PRIMARY KEY,
+  event_type TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  published_at TIMESTAMP WITH TIME ZONE,
+  claimed_until TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events(id) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published_at ON outbox_events(published_at) WHERE published_at IS NOT NULL;
@@ -0,0 +1,11 @@
+-- WebSocket/SSE ulanishi uchun bir martalik, qisqa muddatli ticket'lar (JWT URL'ga tushmasligi uchun).
+-- Ticket'ning o'zi emas, sha256 hash'i saqlanadi.
+CREATE TABLE IF NOT EXISTS ws_tickets (
+  ticket_hash TEXT PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  ip TEXT,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ws_tickets_expires_at ON ws_tickets(expires_at);
@@ -24,9 +24,182 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/attachments/{id}": {
+            "get": {
+                "description": "Attachment kontentini qaytaradi. Faqat chat a'zolari yuklab olishi mumkin,\nhali xabarga bog'lanmagan faylni esa faqat uni yuklagan user ko'ra oladi.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Faylni yuklab olish",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token: Bearer \u003ctoken\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Attachment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Fayl kontenti",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "ID noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization Bearer token yuborilmagan yoki noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "User chat a'zosi emas",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Fayl topilmadi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ichki server xatosi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/attachments/{id}/thumbnails/{size}": {
+            "get": {
+                "description": "Rasm attachment'ining server tomonida yaratilgan JPEG thumbnail'ini qaytaradi.\nO'lchamlar: ` + "`" + `small` + "`" + ` (160px), ` + "`" + `medium` + "`" + ` (640px). Ruxsat qoidalari faylni yuklab olish bilan bir xil.",
+                "produces": [
+                    "image/jpeg"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Rasm thumbnail'ini olish",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token: Bearer \u003ctoken\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Attachment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "small",
+                            "medium"
+                        ],
+                        "type": "string",
+                        "description": "Thumbnail o'lchami",
+                        "name": "size",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JPEG thumbnail",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "ID noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization Bearer token yuborilmagan yoki noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "User chat a'zosi emas",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Fayl yoki thumbnail topilmadi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ichki server xatosi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/chats": {
             "get": {
-                "description": "Joriy foydalanuvchiga tegishli private va group chatlar ro'yxatini qaytaradi.",
+                "description": "Joriy foydalanuvchiga tegishli private va group chatlar ro'yxatini qaytaradi.\nPrivate chatlarda suhbatdoshning ` + "`" + `peer_id` + "`" + ` va ` + "`" + `presence` + "`" + ` qiymatlari ham qaytadi.",
                 "produces": [
                     "application/json"
                 ],
@@ -149,9 +322,1118 @@ const docTemplate = `{
             "delete": {
                 "description": "Berilgan ` + "`" + `chat_id` + "`" + ` bo'yicha chatni o'chiradi.",
                 "tags": [
-                    "chats"
+                    "chats"
+                ],
+                "summary": "Chatni o'chirish",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token: Bearer \u003ctoken\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Chat ID",
+                        "name": "chat_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Muvaffaqiyatli o'chirildi"
+                    },
+                    "400": {
+                        "description": "chat_id noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization Bearer token yuborilmagan yoki noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Chat topilmadi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ichki server xatosi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/chats/{chat_id}/attachments": {
+            "post": {
+                "description": "Joriy foydalanuvchi chatga fayl yoki rasm yuklaydi (multipart/form-data, ` + "`" + `file` + "`" + ` maydoni).\nQaytgan ` + "`" + `id` + "`" + ` keyin ` + "`" + `POST /messages` + "`" + ` dagi ` + "`" + `attachment_ids` + "`" + ` ichida yuboriladi.\n24 soat ichida hech qaysi xabarga bog'lanmagan fayl o'chiriladi.\nFayl turi kontentdan aniqlanadi va config'dagi ruxsat etilgan MIME turlar bilan tekshiriladi.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Fayl yuklash",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token: Bearer \u003ctoken\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Chat ID",
+                        "name": "chat_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Yuklanadigan fayl",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "{\"data\":{\"id\":1,\"file_name\":\"a.png\",\"mime_type\":\"image/png\",\"size_bytes\":1024,\"url\":\"/api/v1/attachments/1\"}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "chat_id, fayl yoki fayl turi noto'g'ri, rasm metadata'sini tozalab bo'lmadi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization Bearer token yuborilmagan yoki noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "User chat a'zosi emas",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Fayl hajmi limitdan katta",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ichki server xatosi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/chats/{chat_id}/mentions/next": {
+            "get": {
+                "description": "Joriy user mention qilingan va hali o'qilmagan keyingi xabar ID sini qaytaradi.\n` + "`" + `after` + "`" + ` berilsa shu xabar ID dan keyingilari qidiriladi. Topilgan ID bilan ` + "`" + `GET /chats/{chat_id}/messages?around=` + "`" + ` chaqiriladi.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Keyingi o'qilmagan mention",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token: Bearer \u003ctoken\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Chat ID",
+                        "name": "chat_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Shu xabar ID dan keyingi mention",
+                        "name": "after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "{\"data\":{\"message_id\":42}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "chat_id yoki after noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization Bearer token yuborilmagan yoki noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "User chat a'zosi emas",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "O'qilmagan mention yo'q",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ichki server xatosi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/chats/{chat_id}/messages": {
+            "get": {
+                "description": "Berilgan chatdagi xabarlar tarixini cursor pagination bilan qaytaradi. Faqat chat a'zosi ko'ra oladi.\n` + "`" + `before` + "`" + `, ` + "`" + `after` + "`" + ` va ` + "`" + `around` + "`" + ` dan faqat bittasi yuboriladi. Hech biri bo'lmasa eng oxirgi xabarlar qaytadi.\n` + "`" + `prev_cursor` + "`" + ` eski xabarlar uchun ` + "`" + `before` + "`" + `, ` + "`" + `next_cursor` + "`" + ` yangi xabarlar uchun ` + "`" + `after` + "`" + ` qiymati sifatida ishlatiladi.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Chat xabarlarini olish",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token: Bearer \u003ctoken\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Chat ID",
+                        "name": "chat_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Sahifadagi xabarlar soni (1..100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Shu xabar ID dan oldingi xabarlar",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Shu xabar ID dan keyingi xabarlar",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Shu xabar ID atrofidagi xabarlar",
+                        "name": "around",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "{\"data\":{\"messages\":[...],\"prev_cursor\":10,\"next_cursor\":null}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "chat_id yoki query param noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization Bearer token yuborilmagan yoki noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "User chat a'zosi emas",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ichki server xatosi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/chats/{chat_id}/ttl": {
+            "patch": {
+                "description": "Chatdagi yangi xabarlar berilgan vaqtdan keyin o'chib ketishini sozlaydi. ` + "`" + `ttl_seconds: 0` + "`" + ` sozlamani o'chiradi.\nGroupda faqat owner/admin, private chatda esa ikkala ishtirokchi ham o'zgartira oladi.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chats"
+                ],
+                "summary": "Disappearing messages sozlamasi",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token: Bearer \u003ctoken\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Chat ID",
+                        "name": "chat_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Xabar yashash muddati (sekund)",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.updateChatTTLRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "{\"data\":{\"result\":\"updated\",\"ttl_seconds\":86400}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "chat_id yoki body noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization Bearer token yuborilmagan yoki noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Ruxsat yo'q",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Chat topilmadi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ichki server xatosi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "get": {
+                "description": "WebSocket ishlamaydigan tarmoqlar uchun zaxira: WebSocket bilan bir xil event payload'larini\n` + "`" + `text/event-stream` + "`" + ` sifatida yuboradi. ` + "`" + `seq` + "`" + `li event'lar ` + "`" + `id:` + "`" + ` bilan keladi; ` + "`" + `Last-Event-ID` + "`" + ` header'i\n(yoki ` + "`" + `last_event_id` + "`" + ` query) berilsa shu seq'dan keyingi event'lar avval qayta yuboriladi.\nKerakli event'lar o'chirilgan bo'lsa ` + "`" + `{\"type\":\"resync_required\",\"last_seq\":N}` + "`" + ` keladi: client\nchatlar va xabarlarni qayta yuklashi kerak. Buyruqlar (typing, presence) SSE orqali yuborilmaydi.\nBrauzer EventSource header yubora olmagani uchun ` + "`" + `POST /ws/ticket` + "`" + ` bergan bir martalik ` + "`" + `?ticket=` + "`" + ` ishlatiladi.\n` + "`" + `auth.allow_query_token` + "`" + ` yoqilgan bo'lsa JWT ` + "`" + `?token=` + "`" + ` orqali ham qabul qilinadi.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "sync"
+                ],
+                "summary": "Realtime event'lar (SSE)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token: Bearer \u003ctoken\u003e",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bir martalik ulanish ticket'i (EventSource uchun)",
+                        "name": "ticket",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "JWT token (allow_query_token yoqilgan bo'lsa)",
+                        "name": "token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Client ko'rgan oxirgi seq",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Client ko'rgan oxirgi seq (birinchi ulanish uchun)",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Event oqimi",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Last-Event-ID noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Token yuborilmagan yoki noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ichki server xatosi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/groups": {
+            "post": {
+                "description": "Yangi group chat yaratadi, joriy userni owner qiladi va ` + "`" + `member_ids` + "`" + ` dagi userlarni qo'shadi.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Group chat yaratish",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token: Bearer \u003ctoken\u003e (owner bo'ladi)",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Group yaratish ma'lumotlari",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.createGroupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "{\"data\":{\"chat_id\":17}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "So'rov noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization Bearer token yuborilmagan yoki noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ichki server xatosi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{chat_id}": {
+            "patch": {
+                "description": "Berilgan ` + "`" + `chat_id` + "`" + ` bo'yicha group nomi va description qiymatlarini yangilaydi.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Group ma'lumotlarini yangilash",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token: Bearer \u003ctoken\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Group chat ID",
+                        "name": "chat_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Yangilanadigan qiymatlar",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.updateGroupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "{\"data\":{\"result\":\"updated\"}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID yoki body noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization Bearer token yuborilmagan yoki noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Group topilmadi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ichki server xatosi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{chat_id}/members": {
+            "get": {
+                "description": "Berilgan group chat uchun a'zolar ro'yxatini qaytaradi. Faqat chat a'zosi ko'ra oladi.\nHar bir a'zo uchun ` + "`" + `presence` + "`" + ` (` + "`" + `online` + "`" + `/` + "`" + `away` + "`" + `/` + "`" + `offline` + "`" + ` va ` + "`" + `last_seen_at` + "`" + `) qo'shiladi.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "members"
+                ],
+                "summary": "Chat a'zolarini olish",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token: Bearer \u003ctoken\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Group chat ID",
+                        "name": "chat_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "{\"data\":[...a'zolar...]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "chat_id noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization Bearer token yuborilmagan yoki noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "User chat a'zosi emas",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ichki server xatosi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Group chatga yangi a'zo qo'shadi. Amalni faqat owner yoki admin bajarishi mumkin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "members"
+                ],
+                "summary": "Groupga a'zo qo'shish",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token: Bearer \u003ctoken\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Group chat ID",
+                        "name": "chat_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Qo'shiladigan user ID",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.addMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "{\"data\":{\"result\":\"added\",\"user_id\":21}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Path param yoki body noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization Bearer token yuborilmagan yoki noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Ruxsat yo'q",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Chat topilmadi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ichki server xatosi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{chat_id}/{user_id}/member": {
+            "delete": {
+                "description": "Groupdan userni chiqaradi. O'zini chiqarish mumkin, boshqa userni esa owner/admin chiqara oladi.",
+                "tags": [
+                    "members"
+                ],
+                "summary": "A'zoni groupdan chiqarish",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token: Bearer \u003ctoken\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Group chat ID",
+                        "name": "chat_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Chiqariladigan user ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Muvaffaqiyatli chiqarildi"
+                    },
+                    "400": {
+                        "description": "Path param noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization Bearer token yuborilmagan yoki noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Ruxsat yo'q",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Member yoki chat topilmadi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ichki server xatosi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "API ishlayotganini tekshirish uchun texnik endpoint.\n` + "`" + `websocket` + "`" + ` - shu instance'dagi ulanishlar, navbati to'lgani uchun yuborilmagan frame'lar\nva shu sababli uzilgan client'lar soni, ` + "`" + `dropped_deliveries` + "`" + ` - bufer to'lgani uchun yozilmagan\n\"yetkazildi\" signallari.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "API holatini tekshirish",
+                "responses": {
+                    "200": {
+                        "description": "{\"status\":\"available\",\"version\":\"v1.0.0\",\"message\":\"Welcome to ChatX API\",\"ENV\":\"dev\",\"websocket\":{...}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Ichki server xatosi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/messages": {
+            "post": {
+                "description": "Joriy foydalanuvchi berilgan chatga yangi xabar yuboradi.\n` + "`" + `reply_to_message_id` + "`" + ` berilsa xabar shu chatdagi boshqa xabarga javob sifatida saqlanadi.\n` + "`" + `send_at` + "`" + ` (RFC3339) berilsa xabar rejalashtiriladi va 202 bilan pending holatda qaytadi.\n` + "`" + `attachment_ids` + "`" + ` oldin ` + "`" + `/chats/{chat_id}/attachments` + "`" + ` orqali yuklangan fayllarni xabarga bog'laydi.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Xabar yuborish",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token: Bearer \u003ctoken\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Xabar yuborish ma'lumotlari",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.createMessageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "{\"data\":{...xabar...}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "202": {
+                        "description": "{\"data\":{...rejalashtirilgan xabar...}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Body, reply_to_message_id, send_at yoki attachment_ids noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization Bearer token yuborilmagan yoki noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "User chat a'zosi emas",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ichki server xatosi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/chats/{chat_id}/read": {
+            "patch": {
+                "description": "Joriy foydalanuvchi uchun berilgan chatdagi barcha kiruvchi xabarlarni o'qilgan holatiga o'tkazadi.\nBoshqa a'zolarga ` + "`" + `messages_read` + "`" + ` eventi ` + "`" + `last_read_message_id` + "`" + ` bilan yuboriladi.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Chatdagi xabarlarni o'qilgan deb belgilash",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token: Bearer \u003ctoken\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Chat ID",
+                        "name": "chat_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "{\"data\":{\"status\":\"success\",\"last_read_message_id\":42}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "chat_id noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization Bearer token yuborilmagan yoki noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "User chat a'zosi emas",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ichki server xatosi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/scheduled": {
+            "get": {
+                "description": "Joriy foydalanuvchining hali yuborilmagan (pending) rejalashtirilgan xabarlarini send_at bo'yicha qaytaradi.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
                 ],
-                "summary": "Chatni o'chirish",
+                "summary": "Rejalashtirilgan xabarlar",
                 "parameters": [
                     {
                         "type": "string",
@@ -159,26 +1441,14 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Chat ID",
-                        "name": "chat_id",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Muvaffaqiyatli o'chirildi"
-                    },
-                    "400": {
-                        "description": "chat_id noto'g'ri",
+                    "200": {
+                        "description": "{\"data\":[...rejalashtirilgan xabarlar...]}",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     },
                     "401": {
@@ -190,15 +1460,6 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Chat topilmadi",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
                     "500": {
                         "description": "Ichki server xatosi",
                         "schema": {
@@ -211,16 +1472,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/chats/{chat_id}/messages": {
-            "get": {
-                "description": "Berilgan chatdagi xabarlar tarixini qaytaradi. Faqat chat a'zosi ko'ra oladi.",
+        "/messages/scheduled/{id}": {
+            "delete": {
+                "description": "Joriy foydalanuvchining hali yuborilmagan rejalashtirilgan xabarini bekor qiladi.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "messages"
                 ],
-                "summary": "Chat xabarlarini olish",
+                "summary": "Rejalashtirilgan xabarni bekor qilish",
                 "parameters": [
                     {
                         "type": "string",
@@ -231,22 +1492,22 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "Chat ID",
-                        "name": "chat_id",
+                        "description": "Rejalashtirilgan xabar ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "{\"data\":[...xabarlar...]}",
+                        "description": "{\"data\":{\"result\":\"cancelled\"}}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "chat_id noto'g'ri",
+                        "description": "ID noto'g'ri",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -263,8 +1524,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "403": {
-                        "description": "User chat a'zosi emas",
+                    "404": {
+                        "description": "Xabar topilmadi yoki allaqachon yuborilgan",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -284,47 +1545,79 @@ const docTemplate = `{
                 }
             }
         },
-        "/groups": {
-            "post": {
-                "description": "Yangi group chat yaratadi, joriy userni owner qiladi va ` + "`" + `member_ids` + "`" + ` dagi userlarni qo'shadi.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/messages/search": {
+            "get": {
+                "description": "Joriy user a'zo bo'lgan barcha chatlardagi xabarlarni full-text qidiradi.\n` + "`" + `q` + "`" + ` websearch sintaksisini qo'llaydi (` + "`" + `\"aniq ibora\"` + "`" + `, ` + "`" + `-istisno` + "`" + `, ` + "`" + `or` + "`" + `).\nNatijalar relevance bo'yicha tartiblanadi, ` + "`" + `snippet` + "`" + ` HTML-escape qilingan, topilgan so'zlar ` + "`" + `\u003cmark\u003e` + "`" + ` bilan belgilanadi.\nKeyingi sahifa uchun ` + "`" + `next_cursor` + "`" + ` qiymati ` + "`" + `cursor` + "`" + ` sifatida yuboriladi. ` + "`" + `context_url` + "`" + ` xabar atrofidagi tarixni ochadi.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "groups"
+                    "messages"
                 ],
-                "summary": "Group chat yaratish",
+                "summary": "Xabarlarni qidirish",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Bearer token: Bearer \u003ctoken\u003e (owner bo'ladi)",
+                        "description": "Bearer token: Bearer \u003ctoken\u003e",
                         "name": "Authorization",
                         "in": "header",
                         "required": true
                     },
                     {
-                        "description": "Group yaratish ma'lumotlari",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.createGroupRequest"
-                        }
+                        "type": "string",
+                        "description": "Qidiruv so'rovi",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Faqat shu chat ichida",
+                        "name": "chat_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Faqat shu user yuborgan xabarlar",
+                        "name": "sender_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Boshlanish vaqti (RFC3339, shu vaqt ham kiradi)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tugash vaqti (RFC3339, shu vaqt kirmaydi)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Natijalar soni (1..50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Oldingi javobdagi next_cursor",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "{\"data\":{\"chat_id\":17}}",
+                    "200": {
+                        "description": "{\"data\":{\"results\":[...],\"next_cursor\":\"...\"}}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "So'rov noto'g'ri",
+                        "description": "Query param noto'g'ri",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -353,19 +1646,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/groups/{chat_id}": {
-            "patch": {
-                "description": "Berilgan ` + "`" + `chat_id` + "`" + ` bo'yicha group nomi va description qiymatlarini yangilaydi.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/messages/{id}": {
+            "delete": {
+                "description": "` + "`" + `scope=me` + "`" + ` xabarni faqat joriy foydalanuvchi uchun yashiradi, boshqa a'zolarga ta'sir qilmaydi.\n` + "`" + `scope=everyone` + "`" + ` (default) xabarni hamma uchun o'chiradi, timeline'da tombstone qoladi.\nYuboruvchi hamma uchun faqat sozlangan vaqt oralig'ida o'chira oladi; group owner/admin istalgan xabarni o'chira oladi.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "groups"
+                    "messages"
                 ],
-                "summary": "Group ma'lumotlarini yangilash",
+                "summary": "Xabarni o'chirish",
                 "parameters": [
                     {
                         "type": "string",
@@ -376,31 +1666,29 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "Group chat ID",
-                        "name": "chat_id",
+                        "description": "Xabar ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Yangilanadigan qiymatlar",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.updateGroupRequest"
-                        }
+                        "type": "string",
+                        "default": "everyone",
+                        "description": "me | everyone",
+                        "name": "scope",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "{\"data\":{\"result\":\"updated\"}}",
+                        "description": "{\"data\":{\"result\":\"deleted\",\"scope\":\"everyone\"}}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "ID yoki body noto'g'ri",
+                        "description": "ID yoki scope noto'g'ri",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -417,8 +1705,17 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "403": {
+                        "description": "Ruxsat yo'q yoki o'chirish muddati o'tgan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "404": {
-                        "description": "Group topilmadi",
+                        "description": "Xabar topilmadi",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -436,18 +1733,19 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/groups/{chat_id}/members": {
-            "get": {
-                "description": "Berilgan group chat uchun a'zolar ro'yxatini qaytaradi. Faqat chat a'zosi ko'ra oladi.",
+            },
+            "patch": {
+                "description": "Joriy foydalanuvchi o'zi yuborgan xabar matnini yangilaydi. Eski matn tahrir tarixiga yoziladi.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "members"
+                    "messages"
                 ],
-                "summary": "Chat a'zolarini olish",
+                "summary": "Xabarni tahrirlash",
                 "parameters": [
                     {
                         "type": "string",
@@ -458,22 +1756,31 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "Group chat ID",
-                        "name": "chat_id",
+                        "description": "Xabar ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Yangilangan xabar matni",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.updateMessageRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "{\"data\":[...a'zolar...]}",
+                        "description": "{\"data\":{\"result\":\"updated\",\"edited_at\":\"...\"}}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "chat_id noto'g'ri",
+                        "description": "ID yoki body noto'g'ri",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -490,8 +1797,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "403": {
-                        "description": "User chat a'zosi emas",
+                    "404": {
+                        "description": "Xabar topilmadi yoki userga tegishli emas",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -509,9 +1816,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/messages/{id}/reactions": {
             "post": {
-                "description": "Group chatga yangi a'zo qo'shadi. Amalni faqat owner yoki admin bajarishi mumkin.",
+                "description": "Joriy foydalanuvchi chatdagi xabarga emoji reaksiya qo'shadi. Bir xil reaksiya qayta yuborilsa o'zgarish bo'lmaydi.\n` + "`" + `emoji` + "`" + ` bitta emoji bo'lishi kerak (teri rangi, bayroq, keycap va ZWJ zanjirlari ham): matn yoki bir nechta emoji 400 qaytaradi.",
                 "consumes": [
                     "application/json"
                 ],
@@ -519,9 +1828,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "members"
+                    "reactions"
                 ],
-                "summary": "Groupga a'zo qo'shish",
+                "summary": "Xabarga reaksiya qo'shish",
                 "parameters": [
                     {
                         "type": "string",
@@ -532,31 +1841,31 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "Group chat ID",
-                        "name": "chat_id",
+                        "description": "Xabar ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Qo'shiladigan user ID",
+                        "description": "Emoji",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.addMemberRequest"
+                            "$ref": "#/definitions/main.reactionRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "{\"data\":{\"result\":\"added\",\"user_id\":21}}",
+                        "description": "{\"data\":{\"result\":\"added\",\"emoji\":\"👍\"}}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Path param yoki body noto'g'ri",
+                        "description": "ID yoki body noto'g'ri",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -574,7 +1883,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Ruxsat yo'q",
+                        "description": "User chat a'zosi emas",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -583,7 +1892,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Chat topilmadi",
+                        "description": "Xabar topilmadi",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -603,13 +1912,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/groups/{chat_id}/{user_id}/member": {
+        "/messages/{id}/reactions/{emoji}": {
             "delete": {
-                "description": "Groupdan userni chiqaradi. O'zini chiqarish mumkin, boshqa userni esa owner/admin chiqara oladi.",
+                "description": "Joriy foydalanuvchi o'zi qo'shgan emoji reaksiyani olib tashlaydi. Emoji path ichida URL-encode qilinadi.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "members"
+                    "reactions"
                 ],
-                "summary": "A'zoni groupdan chiqarish",
+                "summary": "Xabardan reaksiyani olib tashlash",
                 "parameters": [
                     {
                         "type": "string",
@@ -620,25 +1932,29 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "Group chat ID",
-                        "name": "chat_id",
+                        "description": "Xabar ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Chiqariladigan user ID",
-                        "name": "user_id",
+                        "type": "string",
+                        "description": "URL-encode qilingan emoji",
+                        "name": "emoji",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Muvaffaqiyatli chiqarildi"
+                    "200": {
+                        "description": "{\"data\":{\"result\":\"removed\"}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     },
                     "400": {
-                        "description": "Path param noto'g'ri",
+                        "description": "ID yoki emoji noto'g'ri",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -656,7 +1972,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Ruxsat yo'q",
+                        "description": "User chat a'zosi emas",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -665,7 +1981,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Member yoki chat topilmadi",
+                        "description": "Xabar yoki reaksiya topilmadi",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -685,51 +2001,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/health": {
+        "/messages/{id}/receipts": {
             "get": {
-                "description": "API ishlayotganini tekshirish uchun texnik endpoint.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "system"
-                ],
-                "summary": "API holatini tekshirish",
-                "responses": {
-                    "200": {
-                        "description": "{\"status\":\"available\",\"version\":\"v1.0.0\",\"message\":\"Welcome to ChatX API\",\"ENV\":\"dev\"}",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Ichki server xatosi",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    }
-                }
-            }
-        },
-        "/messages": {
-            "post": {
-                "description": "Joriy foydalanuvchi berilgan chatga yangi xabar yuboradi.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Xabar yuboruvchisi uchun: chatning boshqa a'zolari bo'yicha ` + "`" + `sent` + "`" + `/` + "`" + `delivered` + "`" + `/` + "`" + `read` + "`" + ` holati va vaqtlari.\n` + "`" + `delivered_at` + "`" + ` xabar WebSocket client'iga yozilgan vaqt, ` + "`" + `read_at` + "`" + ` chat o'qilgan deb belgilangan vaqt.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "messages"
                 ],
-                "summary": "Xabar yuborish",
+                "summary": "Xabar kimga yetkazilgani va kim o'qigani",
                 "parameters": [
                     {
                         "type": "string",
@@ -739,25 +2020,23 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Xabar yuborish ma'lumotlari",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.createMessageRequest"
-                        }
+                        "type": "integer",
+                        "description": "Xabar ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "{\"data\":{...xabar...}}",
+                    "200": {
+                        "description": "{\"data\":[{\"user_id\":2,\"username\":\"ali\",\"status\":\"read\",\"delivered_at\":\"...\",\"read_at\":\"...\"}]}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Body noto'g'ri",
+                        "description": "ID noto'g'ri",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -775,7 +2054,16 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "User chat a'zosi emas",
+                        "description": "Faqat xabar yuboruvchisi ko'ra oladi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Xabar topilmadi",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -795,16 +2083,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/messages/chats/{chat_id}/read": {
-            "patch": {
-                "description": "Joriy foydalanuvchi uchun berilgan chatdagi barcha kiruvchi xabarlarni o'qilgan holatiga o'tkazadi.",
+        "/messages/{id}/revisions": {
+            "get": {
+                "description": "Xabarning tahrirdan oldingi barcha matnlarini eskidan yangiga qaytaradi. Faqat chat a'zosi ko'ra oladi.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "messages"
                 ],
-                "summary": "Chatdagi xabarlarni o'qilgan deb belgilash",
+                "summary": "Xabar tahrir tarixi",
                 "parameters": [
                     {
                         "type": "string",
@@ -815,22 +2103,22 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "Chat ID",
-                        "name": "chat_id",
+                        "description": "Xabar ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "{\"data\":{\"status\":\"success\"}}",
+                        "description": "{\"data\":[{\"id\":1,\"message_text\":\"...\",\"replaced_at\":\"...\"}]}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "chat_id noto'g'ri",
+                        "description": "ID noto'g'ri",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -856,6 +2144,15 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "404": {
+                        "description": "Xabar topilmadi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Ichki server xatosi",
                         "schema": {
@@ -868,16 +2165,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/messages/{id}": {
-            "delete": {
-                "description": "Joriy foydalanuvchi o'zi yuborgan xabarni o'chiradi.",
+        "/messages/{id}/thread": {
+            "get": {
+                "description": "Root xabarga berilgan javoblarni cursor pagination bilan qaytaradi. Faqat chat a'zosi ko'ra oladi.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "messages"
                 ],
-                "summary": "Xabarni o'chirish",
+                "summary": "Xabarga berilgan javoblar",
                 "parameters": [
                     {
                         "type": "string",
@@ -888,22 +2185,47 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "Xabar ID",
+                        "description": "Root xabar ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Sahifadagi xabarlar soni (1..100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Shu xabar ID dan oldingi javoblar",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Shu xabar ID dan keyingi javoblar",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Shu xabar ID atrofidagi javoblar",
+                        "name": "around",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "{\"data\":{\"result\":\"deleted\"}}",
+                        "description": "{\"data\":{\"messages\":[...],\"prev_cursor\":null,\"next_cursor\":null}}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "ID noto'g'ri",
+                        "description": "ID yoki query param noto'g'ri",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -920,8 +2242,17 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "403": {
+                        "description": "User chat a'zosi emas",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "404": {
-                        "description": "Xabar topilmadi yoki userga tegishli emas",
+                        "description": "Xabar topilmadi",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -939,19 +2270,18 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Joriy foydalanuvchi o'zi yuborgan xabar matnini yangilaydi.",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/sync": {
+            "get": {
+                "description": "Joriy user event oqimidan ` + "`" + `since` + "`" + ` seq'dan keyingi event'larni tartib bilan qaytaradi.\nHar bir event WebSocket orqali kelgan frame bilan bir xil va ` + "`" + `seq` + "`" + ` maydoniga ega.\n` + "`" + `has_more` + "`" + ` bo'lsa oxirgi event seq'i bilan qayta so'raladi. ` + "`" + `truncated` + "`" + ` bo'lsa kerakli event'lar\nsaqlanish muddati o'tib o'chirilgan: client chatlar va xabarlarni to'liq qayta yuklashi kerak.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "messages"
+                    "sync"
                 ],
-                "summary": "Xabarni tahrirlash",
+                "summary": "O'tkazib yuborilgan event'lar",
                 "parameters": [
                     {
                         "type": "string",
@@ -962,31 +2292,29 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "Xabar ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "default": 0,
+                        "description": "Client ko'rgan oxirgi seq",
+                        "name": "since",
+                        "in": "query"
                     },
                     {
-                        "description": "Yangilangan xabar matni",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.updateMessageRequest"
-                        }
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Event'lar soni (1..500)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "{\"data\":{\"result\":\"updated\"}}",
+                        "description": "{\"data\":{\"events\":[...],\"last_seq\":42,\"has_more\":false,\"truncated\":false}}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "ID yoki body noto'g'ri",
+                        "description": "Query param noto'g'ri",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1003,15 +2331,6 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Xabar topilmadi yoki userga tegishli emas",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
                     "500": {
                         "description": "Ichki server xatosi",
                         "schema": {
@@ -1247,6 +2566,131 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/users/privacy": {
+            "patch": {
+                "description": "Joriy user oxirgi ko'ringan vaqtini (` + "`" + `last_seen_at` + "`" + `) boshqalardan yashirishi mumkin.\nYashirilganda online/away/offline holati ko'rinadi, lekin ` + "`" + `last_seen_at` + "`" + ` doim null qaytadi.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Maxfiylik sozlamalari",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token: Bearer \u003ctoken\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Maxfiylik sozlamalari",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.updatePrivacyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "{\"data\":{\"hide_last_seen\":true}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Body noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Authorization Bearer token yuborilmagan yoki noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "User topilmadi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ichki server xatosi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/ws/ticket": {
+            "post": {
+                "description": "` + "`" + `GET /ws` + "`" + ` va ` + "`" + `GET /events` + "`" + ` uchun bir martalik, qisqa muddatli ticket beradi: ` + "`" + `?ticket=\u003cticket\u003e` + "`" + `.\nShunda uzoq yashaydigan JWT URL'ga (proxy va access log'larga) tushmaydi.\nTicket bir marta ishlatiladi va ` + "`" + `auth.ws_ticket.ttl` + "`" + ` (default 30s) ichida amal qiladi.\n` + "`" + `auth.ws_ticket.bind_ip` + "`" + ` yoqilgan bo'lsa faqat so'ragan IP'dan ishlatiladi\n(proxy ortida IP ` + "`" + `auth.ws_ticket.client_ip_header` + "`" + ` header'idan olinadi).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sync"
+                ],
+                "summary": "WebSocket/SSE ulanish ticket'i",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token: Bearer \u003ctoken\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/service.IssuedTicket"
+                        }
+                    },
+                    "401": {
+                        "description": "Token yuborilmagan yoki noto'g'ri",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ichki server xatosi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
@@ -1288,16 +2732,28 @@ const docTemplate = `{
         "main.createMessageRequest": {
             "type": "object",
             "required": [
-                "chat_id",
-                "message_text"
+                "chat_id"
             ],
             "properties": {
+                "attachment_ids": {
+                    "type": "array",
+                    "maxItems": 10,
+                    "items": {
+                        "type": "integer"
+                    }
+                },
                 "chat_id": {
                     "type": "integer"
                 },
                 "message_text": {
                     "type": "string",
                     "maxLength": 4000
+                },
+                "reply_to_message_id": {
+                    "type": "integer"
+                },
+                "send_at": {
+                    "type": "string"
                 }
             }
         },
@@ -1312,6 +2768,28 @@ const docTemplate = `{
                 }
             }
         },
+        "main.reactionRequest": {
+            "type": "object",
+            "required": [
+                "emoji"
+            ],
+            "properties": {
+                "emoji": {
+                    "type": "string",
+                    "maxLength": 32
+                }
+            }
+        },
+        "main.updateChatTTLRequest": {
+            "type": "object",
+            "properties": {
+                "ttl_seconds": {
+                    "type": "integer",
+                    "maximum": 31536000,
+                    "minimum": 0
+                }
+            }
+        },
         "main.updateGroupRequest": {
             "type": "object",
             "required": [
@@ -1340,6 +2818,28 @@ const docTemplate = `{
                 }
             }
         },
+        "main.updatePrivacyRequest": {
+            "type": "object",
+            "required": [
+                "hide_last_seen"
+            ],
+            "properties": {
+                "hide_last_seen": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "service.IssuedTicket": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "ticket": {
+                    "type": "string"
+                }
+            }
+        },
         "service.RequestRegister": {
             "type": "object",
             "required": [
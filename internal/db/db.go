@@ -10,13 +10,18 @@ import (
 	_ "github.com/lib/pq"
 )
 
-func NewPostgres(Addr, Host, User, Password, Name string, MaxIdleConns, MaxOpenConns int, MaxIdletime string) (*sql.DB, error) {
+// DSN - Postgres ulanish satri (LISTEN uchun alohida ulanish ochishda ham kerak)
+func DSN(Addr, Host, User, Password, Name string) string {
 	hostPort := Host
 	if Addr != "" {
 		hostPort = net.JoinHostPort(Host, Addr)
 	}
 
-	dsn := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable", User, Password, hostPort, Name)
+	return fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable", User, Password, hostPort, Name)
+}
+
+func NewPostgres(Addr, Host, User, Password, Name string, MaxIdleConns, MaxOpenConns int, MaxIdletime string) (*sql.DB, error) {
+	dsn := DSN(Addr, Host, User, Password, Name)
 
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
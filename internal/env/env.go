@@ -3,6 +3,7 @@ package env
 import (
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"gopkg.in/yaml.v3"
@@ -40,8 +41,36 @@ type Config struct {
 		FromEmail string `yaml:"fromEmail"`
 	}
 	Auth struct {
-		SecretKey string `yaml:"secret_key"`
+		SecretKey       string `yaml:"secret_key"`
+		AllowQueryToken bool   `yaml:"allow_query_token"`
+		WSTicket        struct {
+			TTL    string `yaml:"ttl"`
+			BindIP bool   `yaml:"bind_ip"`
+			// ClientIPHeader - ishonchli proxy client IP'sini yozadigan header (masalan X-Forwarded-For).
+			// Bo'sh bo'lsa ulanish manzili (RemoteAddr) ishlatiladi.
+			ClientIPHeader string `yaml:"client_ip_header"`
+		} `yaml:"ws_ticket"`
 	}
+	Messages struct {
+		DeleteForEveryoneWindow string `yaml:"delete_for_everyone_window"`
+		EventRetention          string `yaml:"event_retention"`
+	} `yaml:"messages"`
+	Hub struct {
+		// Backplane - "memory" (bitta instance) yoki "postgres" (LISTEN/NOTIFY orqali bir nechta instance)
+		Backplane string `yaml:"backplane"`
+	} `yaml:"hub"`
+	WebSocket struct {
+		ReadTimeout  string `yaml:"read_timeout"`
+		WriteTimeout string `yaml:"write_timeout"`
+		MaxFrameSize int64  `yaml:"max_frame_size"`
+		SendBuffer   int    `yaml:"send_buffer"`
+	} `yaml:"websocket"`
+	Storage struct {
+		Driver           string   `yaml:"driver"`
+		LocalDir         string   `yaml:"local_dir"`
+		MaxUploadSize    int64    `yaml:"max_upload_size"`
+		AllowedMIMETypes []string `yaml:"allowed_mime_types"`
+	} `yaml:"storage"`
 }
 
 func Load() (*Config, error) {
@@ -70,6 +99,10 @@ func Load() (*Config, error) {
 	c.Database.Password = getenv("DB_PASSWORD", c.Database.Password)
 	c.Database.Name = getenv("DB_NAME", c.Database.Name)
 	c.App.APIURL = getenv("API_URL", c.App.APIURL)
+	// ALLOWED_ORIGINS - vergul bilan ajratilgan ro'yxat, YAML'dagi allowed_origins o'rniga ishlatiladi
+	if v := getenv("ALLOWED_ORIGINS", ""); v != "" {
+		c.App.AllowedOrigins = strings.Split(v, ",")
+	}
 
 	if v := getenv("DB_MAX_IDLE_CONNS", ""); v != "" {
 		if parsed, err := strconv.Atoi(v); err == nil {
@@ -94,6 +127,40 @@ func Load() (*Config, error) {
 	c.App.Audience = getenv("JWT_AUDIENCE", c.App.Audience)
 	c.App.Issuer = getenv("JWT_ISSUER", c.App.Issuer)
 	c.Auth.SecretKey = getenv("JWT_SECRET_KEY", c.Auth.SecretKey)
+	if v := getenv("AUTH_ALLOW_QUERY_TOKEN", ""); v != "" {
+		if parsed, err := strconv.ParseBool(v); err == nil {
+			c.Auth.AllowQueryToken = parsed
+		}
+	}
+	c.Auth.WSTicket.TTL = getenv("WS_TICKET_TTL", c.Auth.WSTicket.TTL)
+	if v := getenv("WS_TICKET_BIND_IP", ""); v != "" {
+		if parsed, err := strconv.ParseBool(v); err == nil {
+			c.Auth.WSTicket.BindIP = parsed
+		}
+	}
+	c.Auth.WSTicket.ClientIPHeader = getenv("WS_TICKET_CLIENT_IP_HEADER", c.Auth.WSTicket.ClientIPHeader)
+	c.Messages.DeleteForEveryoneWindow = getenv("MESSAGE_DELETE_WINDOW", c.Messages.DeleteForEveryoneWindow)
+	c.Messages.EventRetention = getenv("MESSAGE_EVENT_RETENTION", c.Messages.EventRetention)
+	c.Hub.Backplane = getenv("HUB_BACKPLANE", c.Hub.Backplane)
+	c.WebSocket.ReadTimeout = getenv("WS_READ_TIMEOUT", c.WebSocket.ReadTimeout)
+	c.WebSocket.WriteTimeout = getenv("WS_WRITE_TIMEOUT", c.WebSocket.WriteTimeout)
+	if v := getenv("WS_MAX_FRAME_SIZE", ""); v != "" {
+		if parsed, err := strconv.ParseInt(v, 10, 64); err == nil {
+			c.WebSocket.MaxFrameSize = parsed
+		}
+	}
+	if v := getenv("WS_SEND_BUFFER", ""); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil {
+			c.WebSocket.SendBuffer = parsed
+		}
+	}
+	c.Storage.Driver = getenv("STORAGE_DRIVER", c.Storage.Driver)
+	c.Storage.LocalDir = getenv("STORAGE_LOCAL_DIR", c.Storage.LocalDir)
+	if v := getenv("STORAGE_MAX_UPLOAD_SIZE", ""); v != "" {
+		if parsed, err := strconv.ParseInt(v, 10, 64); err == nil {
+			c.Storage.MaxUploadSize = parsed
+		}
+	}
 
 	return c, nil
 }
@@ -0,0 +1,126 @@
+package media
+
+import (
+	"image"
+	"math"
+	"strings"
+)
+
+const base83Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// Blurhash - rasm uchun qisqa blurhash placeholder qaytaradi (https://blurha.sh).
+// Client to'liq rasm yoki thumbnail kelguncha shu satrdan xira fon chizadi.
+func Blurhash(img *image.RGBA, xComponents, yComponents int) string {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return ""
+	}
+
+	factors := make([][3]float64, 0, xComponents*yComponents)
+	for j := 0; j < yComponents; j++ {
+		for i := 0; i < xComponents; i++ {
+			factors = append(factors, basisFactor(img, i, j))
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(encodeBase83((xComponents-1)+(yComponents-1)*9, 1))
+
+	dc, ac := factors[0], factors[1:]
+
+	maxValue := 1.0
+	if len(ac) > 0 {
+		actualMax := 0.0
+		for _, f := range ac {
+			actualMax = math.Max(actualMax, math.Max(math.Abs(f[0]), math.Max(math.Abs(f[1]), math.Abs(f[2]))))
+		}
+		quantisedMax := clampInt(int(math.Floor(actualMax*166-0.5)), 0, 82)
+		maxValue = float64(quantisedMax+1) / 166
+		sb.WriteString(encodeBase83(quantisedMax, 1))
+	} else {
+		sb.WriteString(encodeBase83(0, 1))
+	}
+
+	sb.WriteString(encodeBase83(encodeDC(dc), 4))
+	for _, f := range ac {
+		sb.WriteString(encodeBase83(encodeAC(f, maxValue), 2))
+	}
+
+	return sb.String()
+}
+
+func basisFactor(img *image.RGBA, i, j int) [3]float64 {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	var r, g, b float64
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			basis := math.Cos(math.Pi*float64(i)*float64(x)/float64(width)) *
+				math.Cos(math.Pi*float64(j)*float64(y)/float64(height))
+			off := img.PixOffset(bounds.Min.X+x, bounds.Min.Y+y)
+			r += basis * srgbToLinear(img.Pix[off])
+			g += basis * srgbToLinear(img.Pix[off+1])
+			b += basis * srgbToLinear(img.Pix[off+2])
+		}
+	}
+
+	normalisation := 2.0
+	if i == 0 && j == 0 {
+		normalisation = 1.0
+	}
+	scale := normalisation / float64(width*height)
+
+	return [3]float64{r * scale, g * scale, b * scale}
+}
+
+func encodeDC(v [3]float64) int {
+	return linearToSRGB(v[0])<<16 + linearToSRGB(v[1])<<8 + linearToSRGB(v[2])
+}
+
+func encodeAC(v [3]float64, maxValue float64) int {
+	quant := func(c float64) int {
+		return clampInt(int(math.Floor(signPow(c/maxValue, 0.5)*9+9.5)), 0, 18)
+	}
+	return quant(v[0])*19*19 + quant(v[1])*19 + quant(v[2])
+}
+
+func encodeBase83(value, length int) string {
+	result := make([]byte, length)
+	for i := 1; i <= length; i++ {
+		digit := (value / int(math.Pow(83, float64(length-i)))) % 83
+		result[i-1] = base83Chars[digit]
+	}
+	return string(result)
+}
+
+func srgbToLinear(c uint8) float64 {
+	v := float64(c) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(v float64) int {
+	v = math.Max(0, math.Min(1, v))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(v, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
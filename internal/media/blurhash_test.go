@@ -0,0 +1,87 @@
+package media
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func solidRGBA(w, h int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	return img
+}
+
+func TestBlurhash(t *testing.T) {
+	// Kattaroq rasmning bir qismi: Bounds().Min nol emas
+	framed := solidRGBA(16, 16, color.Black)
+	draw.Draw(framed, image.Rect(4, 4, 12, 10), image.NewUniform(color.White), image.Point{}, draw.Src)
+	sub := framed.SubImage(image.Rect(4, 4, 12, 10)).(*image.RGBA)
+
+	tests := []struct {
+		name   string
+		img    *image.RGBA
+		xc, yc int
+		// wantSize - birinchi belgi: (xc-1) + (yc-1)*9
+		wantSize byte
+		// wantDC - 4 belgili o'rtacha rang
+		wantDC string
+	}{
+		{name: "white 4x3", img: solidRGBA(8, 6, color.White), xc: 4, yc: 3, wantSize: 'L', wantDC: "TSUA"},
+		{name: "black 4x3", img: solidRGBA(8, 6, color.Black), xc: 4, yc: 3, wantSize: 'L', wantDC: "0000"},
+		{name: "white 1x1 has no ac", img: solidRGBA(8, 6, color.White), xc: 1, yc: 1, wantSize: '0', wantDC: "TSUA"},
+		{name: "white 9x9", img: solidRGBA(8, 6, color.White), xc: 9, yc: 9, wantSize: '|', wantDC: "TSUA"},
+		{name: "sub image", img: sub, xc: 4, yc: 3, wantSize: 'L', wantDC: "TSUA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Blurhash(tt.img, tt.xc, tt.yc)
+			if wantLen := 6 + 2*(tt.xc*tt.yc-1); len(got) != wantLen {
+				t.Fatalf("len(%q) = %d, want %d", got, len(got), wantLen)
+			}
+			if got[0] != tt.wantSize {
+				t.Fatalf("size flag = %q, want %q", got[0], tt.wantSize)
+			}
+			if got[2:6] != tt.wantDC {
+				t.Fatalf("dc = %q, want %q", got[2:6], tt.wantDC)
+			}
+			if tt.xc*tt.yc == 1 && got[1] != '0' {
+				t.Fatalf("max ac = %q, want '0'", got[1])
+			}
+		})
+	}
+
+	t.Run("sub image matches standalone image", func(t *testing.T) {
+		if got, want := Blurhash(sub, 4, 3), Blurhash(solidRGBA(8, 6, color.White), 4, 3); got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("empty image", func(t *testing.T) {
+		if got := Blurhash(image.NewRGBA(image.Rect(0, 0, 0, 0)), 4, 3); got != "" {
+			t.Fatalf("got %q, want empty", got)
+		}
+	})
+}
+
+func TestEncodeBase83(t *testing.T) {
+	tests := []struct {
+		value, length int
+		want          string
+	}{
+		{0, 1, "0"},
+		{82, 1, "~"},
+		{83, 2, "10"},
+		{3429, 2, "fQ"},
+		{0xFFFFFF, 4, "TSUA"},
+		{5, 4, "0005"},
+	}
+
+	for _, tt := range tests {
+		if got := encodeBase83(tt.value, tt.length); got != tt.want {
+			t.Errorf("encodeBase83(%d, %d) = %q, want %q", tt.value, tt.length, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+const (
+	tagOrientation = 0x0112
+	tagGPSInfo     = 0x8825
+)
+
+var exifHeader = []byte("Exif\x00\x00")
+
+// StripJPEGLocation - JPEG ichidagi EXIF GPS ma'lumotlarini joyida (in-place) nolga aylantiradi.
+// Fayl qayta encode qilinmaydi, shuning uchun sifat yo'qolmaydi va orientation kabi
+// boshqa teglar saqlanib qoladi. EXIF orientation qiymati qaytariladi (topilmasa 1).
+func StripJPEGLocation(data []byte) int {
+	orientation := 1
+
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return orientation
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			break
+		}
+		marker := data[pos+1]
+		// Segmentlar orasidagi 0xFF to'ldiruvchi baytlar
+		if marker == 0xFF {
+			pos++
+			continue
+		}
+		// SOS dan keyin rasm datasi boshlanadi, metadata segmentlari tugaydi
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			break
+		}
+
+		segment := data[pos+4 : end]
+		if marker == 0xE1 && bytes.HasPrefix(segment, exifHeader) {
+			if o := stripTIFFLocation(segment[len(exifHeader):]); o != 0 {
+				orientation = o
+			}
+		}
+
+		pos = end
+	}
+
+	return orientation
+}
+
+// stripTIFFLocation - TIFF strukturadagi IFD0 ni o'qiydi: orientation'ni qaytaradi
+// va GPS IFD'ni (qiymatlari bilan birga) tozalaydi
+func stripTIFFLocation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	orientation := 0
+	ifd0 := int(order.Uint32(tiff[4:]))
+
+	eachIFDEntry(tiff, order, ifd0, func(entry []byte) {
+		switch order.Uint16(entry) {
+		case tagOrientation:
+			orientation = int(order.Uint16(entry[8:]))
+		case tagGPSInfo:
+			clearIFD(tiff, order, int(order.Uint32(entry[8:])))
+		}
+	})
+
+	if orientation < 1 || orientation > 8 {
+		orientation = 0
+	}
+
+	return orientation
+}
+
+func eachIFDEntry(tiff []byte, order binary.ByteOrder, offset int, fn func(entry []byte)) {
+	if offset < 0 || offset+2 > len(tiff) {
+		return
+	}
+
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		start := offset + 2 + i*12
+		if start+12 > len(tiff) {
+			return
+		}
+		fn(tiff[start : start+12])
+	}
+}
+
+// clearIFD - IFD'dagi barcha yozuvlarni va 4 baytdan katta qiymatlarni nolga aylantiradi
+func clearIFD(tiff []byte, order binary.ByteOrder, offset int) {
+	if offset < 0 || offset+2 > len(tiff) {
+		return
+	}
+
+	eachIFDEntry(tiff, order, offset, func(entry []byte) {
+		size := tiffTypeSize(order.Uint16(entry[2:])) * int(order.Uint32(entry[4:]))
+		if size > 4 {
+			valueOffset := int(order.Uint32(entry[8:]))
+			if valueOffset >= 0 && size <= len(tiff) && valueOffset <= len(tiff)-size {
+				clear(tiff[valueOffset : valueOffset+size])
+			}
+		}
+		clear(entry)
+	})
+
+	order.PutUint16(tiff[offset:], 0)
+}
+
+func tiffTypeSize(t uint16) int {
+	switch t {
+	case 1, 2, 6, 7:
+		return 1
+	case 3, 8:
+		return 2
+	case 4, 9, 11:
+		return 4
+	case 5, 10, 12:
+		return 8
+	default:
+		return 0
+	}
+}
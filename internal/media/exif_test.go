@@ -0,0 +1,218 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// tiffFixture - IFD0 (orientation + GPS pointer) va GPS IFD'dan (ichki ASCII va 24 baytli
+// RATIONAL qiymat) iborat TIFF. Nol bo'lmagan maydonlar standart offset'lar o'rniga ishlatiladi.
+type tiffFixture struct {
+	order       binary.ByteOrder
+	orientation uint16
+	ifd0Count   uint16
+	gpsOffset   uint32
+	valueOffset uint32
+}
+
+const (
+	fixtureGPSOffset   = 38
+	fixtureValueOffset = 68
+	fixtureTIFFSize    = 92
+)
+
+func (f tiffFixture) build() []byte {
+	order := f.order
+	if order == nil {
+		order = binary.BigEndian
+	}
+	ifd0Count, gpsOffset, valueOffset := f.ifd0Count, f.gpsOffset, f.valueOffset
+	if ifd0Count == 0 {
+		ifd0Count = 2
+	}
+	if gpsOffset == 0 {
+		gpsOffset = fixtureGPSOffset
+	}
+	if valueOffset == 0 {
+		valueOffset = fixtureValueOffset
+	}
+
+	tiff := make([]byte, fixtureTIFFSize)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+
+	// IFD0
+	order.PutUint16(tiff[8:], ifd0Count)
+	entry := tiff[10:]
+	order.PutUint16(entry[0:], tagOrientation)
+	order.PutUint16(entry[2:], 3)
+	order.PutUint32(entry[4:], 1)
+	order.PutUint16(entry[8:], f.orientation)
+	entry = tiff[22:]
+	order.PutUint16(entry[0:], tagGPSInfo)
+	order.PutUint16(entry[2:], 4)
+	order.PutUint32(entry[4:], 1)
+	order.PutUint32(entry[8:], gpsOffset)
+
+	// GPS IFD: GPSLatitudeRef "N" va GPSLatitude (3 ta RATIONAL)
+	gps := tiff[fixtureGPSOffset:]
+	order.PutUint16(gps[0:], 2)
+	order.PutUint16(gps[2:], 1)
+	order.PutUint16(gps[4:], 2)
+	order.PutUint32(gps[6:], 2)
+	copy(gps[10:], "N\x00")
+	order.PutUint16(gps[14:], 2)
+	order.PutUint16(gps[16:], 5)
+	order.PutUint32(gps[18:], 3)
+	order.PutUint32(gps[22:], valueOffset)
+	for i := fixtureValueOffset; i < fixtureTIFFSize; i++ {
+		tiff[i] = 0x41
+	}
+
+	return tiff
+}
+
+func jpegSegment(marker byte, body []byte) []byte {
+	seg := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(body)+2))
+	return append(seg, body...)
+}
+
+// jpegFixture - SOI + berilgan segmentlar + SOS va bir necha bayt rasm datasi + EOI
+func jpegFixture(segments ...[]byte) []byte {
+	data := []byte{0xFF, 0xD8}
+	for _, s := range segments {
+		data = append(data, s...)
+	}
+	data = append(data, jpegSegment(0xDA, []byte{1, 2, 3})...)
+	return append(data, 0x11, 0x22, 0xFF, 0x00, 0x33, 0xFF, 0xD9)
+}
+
+func exifSegment(tiff []byte) []byte {
+	return jpegSegment(0xE1, append(append([]byte{}, exifHeader...), tiff...))
+}
+
+func TestStripJPEGLocation(t *testing.T) {
+	tiffStart := 2 + 4 + len(exifHeader)
+
+	tests := []struct {
+		name            string
+		data            []byte
+		wantOrientation int
+		// wantGPSCleared - GPS IFD va uning qiymatlari nolga aylanganini tekshirish
+		wantGPSCleared bool
+	}{
+		{
+			name:            "big endian gps and orientation",
+			data:            jpegFixture(exifSegment(tiffFixture{orientation: 6}.build())),
+			wantOrientation: 6,
+			wantGPSCleared:  true,
+		},
+		{
+			name:            "little endian",
+			data:            jpegFixture(exifSegment(tiffFixture{order: binary.LittleEndian, orientation: 3}.build())),
+			wantOrientation: 3,
+			wantGPSCleared:  true,
+		},
+		{
+			name:            "invalid orientation falls back to 1",
+			data:            jpegFixture(exifSegment(tiffFixture{orientation: 42}.build())),
+			wantOrientation: 1,
+			wantGPSCleared:  true,
+		},
+		{
+			name:            "exif after other segments",
+			data:            jpegFixture(jpegSegment(0xE0, []byte("JFIF\x00\x01\x01")), exifSegment(tiffFixture{orientation: 8}.build())),
+			wantOrientation: 8,
+		},
+		{
+			name:            "fill bytes before exif",
+			data:            insertAt(jpegFixture(exifSegment(tiffFixture{orientation: 5}.build())), 2, []byte{0xFF, 0xFF}),
+			wantOrientation: 5,
+		},
+		{
+			name:            "gps ifd offset out of bounds",
+			data:            jpegFixture(exifSegment(tiffFixture{orientation: 6, gpsOffset: 5000}.build())),
+			wantOrientation: 6,
+		},
+		{
+			name:            "gps ifd offset near uint32 max",
+			data:            jpegFixture(exifSegment(tiffFixture{orientation: 6, gpsOffset: 0xFFFFFFF0}.build())),
+			wantOrientation: 6,
+		},
+		{
+			name:            "gps value offset out of bounds",
+			data:            jpegFixture(exifSegment(tiffFixture{orientation: 6, valueOffset: 9999}.build())),
+			wantOrientation: 6,
+		},
+		{
+			name:            "ifd0 entry count larger than segment",
+			data:            jpegFixture(exifSegment(tiffFixture{orientation: 6, ifd0Count: 0xFFFF}.build())),
+			wantOrientation: 6,
+			wantGPSCleared:  true,
+		},
+		{
+			name:            "truncated ifd0 entry is ignored",
+			data:            jpegFixture(exifSegment(tiffFixture{orientation: 6}.build()[:20])),
+			wantOrientation: 1,
+		},
+		{
+			name:            "unknown byte order",
+			data:            jpegFixture(exifSegment(append([]byte("XX"), tiffFixture{orientation: 6}.build()[2:]...))),
+			wantOrientation: 1,
+		},
+		{
+			name:            "segment length past end of file",
+			data:            []byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF, 0xFF, 'E', 'x'},
+			wantOrientation: 1,
+		},
+		{
+			name:            "not a jpeg",
+			data:            []byte("\x89PNG\r\n\x1a\n"),
+			wantOrientation: 1,
+		},
+		{
+			name:            "empty",
+			data:            nil,
+			wantOrientation: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := append([]byte{}, tt.data...)
+
+			if got := StripJPEGLocation(data); got != tt.wantOrientation {
+				t.Fatalf("orientation = %d, want %d", got, tt.wantOrientation)
+			}
+			if len(data) != len(tt.data) {
+				t.Fatalf("length changed: %d -> %d", len(tt.data), len(data))
+			}
+
+			if tt.wantGPSCleared {
+				tiff := data[tiffStart : tiffStart+fixtureTIFFSize]
+				if !isZero(tiff[fixtureGPSOffset:]) {
+					t.Fatalf("gps ifd not cleared: % x", tiff[fixtureGPSOffset:])
+				}
+				if bytes.Contains(data, []byte("N\x00")) {
+					t.Fatal("gps latitude ref still present")
+				}
+			}
+		})
+	}
+}
+
+func isZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
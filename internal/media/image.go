@@ -0,0 +1,191 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+)
+
+const (
+	// maxPixels - bundan katta rasmlar decode qilinmaydi (xotira uchun himoya: decode va
+	// RGBA nusxasi birga ~8 bayt/piksel oladi). 24 MP zamonaviy telefon kameralariga yetadi.
+	maxPixels        = 24_000_000
+	thumbnailQuality = 80
+	placeholderSize  = 32
+)
+
+var ErrUnsupportedImage = errors.New("unsupported image")
+
+type ThumbnailSize struct {
+	Name   string
+	MaxDim int
+}
+
+// ThumbnailSizes - server generatsiya qiladigan qat'iy o'lchamlar
+var ThumbnailSizes = []ThumbnailSize{
+	{Name: "small", MaxDim: 160},
+	{Name: "medium", MaxDim: 640},
+}
+
+type Thumbnail struct {
+	Size   string
+	Width  int
+	Height int
+	Data   []byte
+}
+
+type ImageInfo struct {
+	Width       int
+	Height      int
+	Placeholder string
+	Thumbnails  []Thumbnail
+}
+
+// ProcessImage - rasmning o'lchamlarini, blurhash placeholder'ini va thumbnail'larni hisoblaydi.
+// Metadata bu yerda tozalanmaydi, data oldin StripMetadata'dan o'tgan bo'lishi kerak.
+// Decode qilib bo'lmaydigan formatlar uchun ErrUnsupportedImage qaytadi.
+func ProcessImage(data []byte, mimeType string) (*ImageInfo, error) {
+	orientation := 1
+	if mimeType == "image/jpeg" {
+		// Tozalangan faylda GPS allaqachon yo'q, bu yerda faqat orientation o'qiladi
+		orientation = StripJPEGLocation(data)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrUnsupportedImage
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+
+	flat := flatten(src)
+	info := &ImageInfo{
+		Width:  cfg.Width,
+		Height: cfg.Height,
+	}
+	if orientation >= 5 {
+		info.Width, info.Height = info.Height, info.Width
+	}
+
+	for _, size := range ThumbnailSizes {
+		thumb := orient(resize(flat, size.MaxDim), orientation)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+			return nil, err
+		}
+
+		info.Thumbnails = append(info.Thumbnails, Thumbnail{
+			Size:   size.Name,
+			Width:  thumb.Bounds().Dx(),
+			Height: thumb.Bounds().Dy(),
+			Data:   buf.Bytes(),
+		})
+	}
+
+	info.Placeholder = Blurhash(orient(resize(flat, placeholderSize), orientation), 4, 3)
+
+	return info, nil
+}
+
+// flatten - rasmni oq fon ustiga chizib, shaffofliksiz RGBA ga o'tkazadi
+func flatten(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Over)
+	return dst
+}
+
+// resize - eng katta tomoni maxDim dan oshmaydigan qilib kichraytiradi (box filter).
+// Rasm allaqachon kichik bo'lsa o'zi qaytadi.
+func resize(src *image.RGBA, maxDim int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	if sw <= maxDim && sh <= maxDim {
+		return src
+	}
+
+	dw, dh := maxDim, sh*maxDim/sw
+	if sh > sw {
+		dw, dh = sw*maxDim/sh, maxDim
+	}
+	dw, dh = max(dw, 1), max(dh, 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*sh/dh, max((y+1)*sh/dh, y*sh/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*sw/dw, max((x+1)*sw/dw, x*sw/dw+1)
+
+			var r, g, b, n int
+			for sy := y0; sy < y1; sy++ {
+				off := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += int(src.Pix[off])
+					g += int(src.Pix[off+1])
+					b += int(src.Pix[off+2])
+					off += 4
+					n++
+				}
+			}
+
+			off := dst.PixOffset(x, y)
+			dst.Pix[off] = uint8(r / n)
+			dst.Pix[off+1] = uint8(g / n)
+			dst.Pix[off+2] = uint8(b / n)
+			dst.Pix[off+3] = 0xFF
+		}
+	}
+
+	return dst
+}
+
+// orient - EXIF orientation bo'yicha rasmni burib/aylantirib to'g'ri holatga keltiradi
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+
+	return dst
+}
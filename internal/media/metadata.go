@@ -0,0 +1,275 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+// ErrMetadataNotStripped - fayl turi uchun metadata tozalash qo'llab-quvvatlanmaydi yoki fayl buzilgan
+var ErrMetadataNotStripped = errors.New("image metadata cannot be stripped")
+
+var (
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+	gifXMPApp    = []byte("XMP DataXMP")
+
+	// JPEG APP1 XMP segmentlari: asosiy paket va katta paketlar uchun extended XMP
+	jpegXMPHeaders = [][]byte{
+		[]byte("http://ns.adobe.com/xap/1.0/\x00"),
+		[]byte("http://ns.adobe.com/xmp/extension/\x00"),
+	}
+)
+
+// StripMetadata - rasmdan joylashuv va boshqa shaxsiy metadata'ni olib tashlaydi.
+// JPEG'da EXIF GPS joyida tozalanadi (orientation kerak) va XMP segmentlari chiqarib tashlanadi,
+// PNG, WebP va GIF'da esa EXIF/XMP/matn bloklari butunlay chiqarib tashlanadi.
+// Boshqa turlar va o'qib bo'lmaydigan fayllar uchun ErrMetadataNotStripped qaytadi:
+// bunday fayl metadata bilan saqlanmasligi kerak.
+func StripMetadata(data []byte, mimeType string) ([]byte, error) {
+	switch mimeType {
+	case "image/jpeg":
+		return stripJPEG(data)
+	case "image/png":
+		return stripPNG(data)
+	case "image/webp":
+		return stripWebP(data)
+	case "image/gif":
+		return stripGIF(data)
+	default:
+		return nil, ErrMetadataNotStripped
+	}
+}
+
+// stripJPEG - EXIF GPS'ni tozalaydi va APP1 XMP segmentlarini (editorlar GPS'ni
+// exif:GPSLatitude sifatida shu yerga ham yozadi) tashlab yuboradi. SOS'dan keyingi rasm datasi
+// o'zgarishsiz ko'chiriladi.
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, ErrMetadataNotStripped
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+
+	pos := 2
+	for {
+		if pos+2 > len(data) || data[pos] != 0xFF {
+			return nil, ErrMetadataNotStripped
+		}
+		marker := data[pos+1]
+		// Segmentlar orasidagi 0xFF to'ldiruvchi baytlar
+		if marker == 0xFF {
+			pos++
+			continue
+		}
+		// SOS (yoki metadata'siz tugagan fayl) - qolgan qismi rasm datasi
+		if marker == 0xDA || marker == 0xD9 {
+			return append(out, data[pos:]...), nil
+		}
+		if pos+4 > len(data) {
+			return nil, ErrMetadataNotStripped
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, ErrMetadataNotStripped
+		}
+
+		segment := data[pos+4 : end]
+		switch {
+		case marker == 0xE1 && isJPEGXMP(segment):
+		case marker == 0xE1 && bytes.HasPrefix(segment, exifHeader):
+			stripTIFFLocation(segment[len(exifHeader):])
+			out = append(out, data[pos:end]...)
+		default:
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+}
+
+func isJPEGXMP(segment []byte) bool {
+	for _, header := range jpegXMPHeaders {
+		if bytes.HasPrefix(segment, header) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripPNG - eXIf va matn (tEXt, zTXt, iTXt - XMP shu yerda bo'ladi) chunk'larini tashlab yuboradi.
+// Qolgan chunk'lar CRC'lari bilan o'zgarishsiz ko'chiriladi.
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, ErrMetadataNotStripped
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+
+	pos := len(pngSignature)
+	for pos < len(data) {
+		if pos+12 > len(data) {
+			return nil, ErrMetadataNotStripped
+		}
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, ErrMetadataNotStripped
+		}
+
+		chunkType := string(data[pos+4 : pos+8])
+		crc := binary.BigEndian.Uint32(data[end-4:])
+		if crc32.ChecksumIEEE(data[pos+4:end-4]) != crc {
+			return nil, ErrMetadataNotStripped
+		}
+
+		switch chunkType {
+		case "eXIf", "tEXt", "zTXt", "iTXt":
+		default:
+			out = append(out, data[pos:end]...)
+		}
+
+		pos = end
+		if chunkType == "IEND" {
+			break
+		}
+	}
+
+	return out, nil
+}
+
+// stripWebP - RIFF container'dan EXIF va XMP chunk'larini olib tashlaydi,
+// VP8X header'idagi tegishli flag'larni o'chiradi va RIFF hajmini yangilaydi
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrMetadataNotStripped
+	}
+
+	const (
+		vp8xFlagXMP  = 0x04
+		vp8xFlagEXIF = 0x08
+	)
+
+	out := make([]byte, 12, len(data))
+	copy(out, data[:12])
+
+	pos := 12
+	for pos < len(data) {
+		if pos+8 > len(data) {
+			return nil, ErrMetadataNotStripped
+		}
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size
+		if end > len(data) {
+			return nil, ErrMetadataNotStripped
+		}
+		// Toq uzunlikdagi chunk'dan keyin bitta to'ldiruvchi bayt bo'ladi
+		if size&1 == 1 && end < len(data) {
+			end++
+		}
+
+		switch string(data[pos : pos+4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			start := len(out)
+			out = append(out, data[pos:end]...)
+			if size > 0 {
+				out[start+8] &^= vp8xFlagEXIF | vp8xFlagXMP
+			}
+		default:
+			out = append(out, data[pos:end]...)
+		}
+
+		pos = end
+	}
+
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
+
+// stripGIF - XMP application extension va comment extension bloklarini olib tashlaydi.
+// GIF'da EXIF yo'q, lekin muharrirlar XMP'ni (GPS bilan) shu bloklarga yozadi.
+func stripGIF(data []byte) ([]byte, error) {
+	if len(data) < 13 || (string(data[:6]) != "GIF87a" && string(data[:6]) != "GIF89a") {
+		return nil, ErrMetadataNotStripped
+	}
+
+	pos := 13
+	if flags := data[10]; flags&0x80 != 0 {
+		pos += 3 << (int(flags&0x07) + 1)
+	}
+	if pos > len(data) {
+		return nil, ErrMetadataNotStripped
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:pos]...)
+
+	for pos < len(data) {
+		start := pos
+		switch data[pos] {
+		case 0x3B: // trailer
+			return append(out, data[pos]), nil
+
+		case 0x21: // extension
+			if pos+2 > len(data) {
+				return nil, ErrMetadataNotStripped
+			}
+			label := data[pos+1]
+			end, ok := skipGIFSubBlocks(data, pos+2)
+			if !ok {
+				return nil, ErrMetadataNotStripped
+			}
+			pos = end
+
+			isXMP := label == 0xFF && start+3+len(gifXMPApp) <= len(data) &&
+				data[start+2] == 11 && bytes.Equal(data[start+3:start+3+len(gifXMPApp)], gifXMPApp)
+			if label == 0xFE || isXMP {
+				continue
+			}
+
+		case 0x2C: // image descriptor
+			if pos+10 > len(data) {
+				return nil, ErrMetadataNotStripped
+			}
+			flags := data[pos+9]
+			pos += 10
+			if flags&0x80 != 0 {
+				pos += 3 << (int(flags&0x07) + 1)
+			}
+			// LZW minimum code size, keyin rasm datasi sub-block'lari
+			end, ok := skipGIFSubBlocks(data, pos+1)
+			if !ok {
+				return nil, ErrMetadataNotStripped
+			}
+			pos = end
+
+		default:
+			return nil, ErrMetadataNotStripped
+		}
+
+		out = append(out, data[start:pos]...)
+	}
+
+	// Trailer'siz fayl: decoder'lar odatda qabul qiladi, tozalangan qismi qaytariladi
+	return out, nil
+}
+
+// skipGIFSubBlocks - pos'dan boshlanadigan sub-block'lar zanjiridan (0 uzunlikdagi
+// terminator bilan birga) keyingi pozitsiyani qaytaradi
+func skipGIFSubBlocks(data []byte, pos int) (int, bool) {
+	for {
+		if pos >= len(data) {
+			return 0, false
+		}
+		size := int(data[pos])
+		pos++
+		if size == 0 {
+			return pos, true
+		}
+		pos += size
+	}
+}
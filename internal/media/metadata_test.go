@@ -0,0 +1,284 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+func pngChunk(chunkType string, body []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(body)))
+	chunk = append(chunk, chunkType...)
+	chunk = append(chunk, body...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+func webpChunk(fourCC string, body []byte) []byte {
+	chunk := append([]byte(fourCC), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	chunk = append(chunk, body...)
+	if len(body)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+func webpFixture(chunks ...[]byte) []byte {
+	data := []byte("RIFF\x00\x00\x00\x00WEBP")
+	for _, c := range chunks {
+		data = append(data, c...)
+	}
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(data)-8))
+	return data
+}
+
+func testImage() *image.Paletted {
+	img := image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{color.Black, color.White})
+	img.SetColorIndex(1, 1, 1)
+	return img
+}
+
+func encodedPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage()); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodedGIF(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, testImage(), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// insertAt - data'ning offset pozitsiyasiga qo'shimcha bloklarni joylaydi
+func insertAt(data []byte, offset int, blocks ...[]byte) []byte {
+	out := append([]byte{}, data[:offset]...)
+	for _, b := range blocks {
+		out = append(out, b...)
+	}
+	return append(out, data[offset:]...)
+}
+
+func TestStripMetadata(t *testing.T) {
+	xmp := []byte(`<x:xmpmeta><exif:GPSLatitude>41,18.5N</exif:GPSLatitude></x:xmpmeta>`)
+
+	rawPNG := encodedPNG(t)
+	// Signature (8) + IHDR (25) dan keyin
+	pngMetaOffset := 33
+
+	rawGIF := encodedGIF(t)
+	// Header (6) + logical screen descriptor (7) + 2 rangli global palette (6) dan keyin
+	gifMetaOffset := 19
+	gifXMP := append(append([]byte{0x21, 0xFF, 11}, gifXMPApp...), 4, 'G', 'P', 'S', '!', 0)
+	gifComment := []byte{0x21, 0xFE, 3, 'g', 'p', 's', 0}
+	// Netscape looping extension - metadata emas, saqlanishi kerak
+	gifLoop := append(append([]byte{0x21, 0xFF, 11}, "NETSCAPE2.0"...), 3, 1, 0, 0, 0)
+
+	vp8x := func(flags byte) []byte { return webpChunk("VP8X", []byte{flags, 0, 0, 0, 3, 0, 0, 3, 0, 0}) }
+	// VP8L toq uzunlikda: to'ldiruvchi bayt bilan birga ko'chirilishi kerak
+	vp8l := webpChunk("VP8L", []byte{0x2F, 1, 2})
+
+	exif := exifSegment(tiffFixture{orientation: 6}.build())
+	strippedEXIF := append([]byte{0xFF, 0xD8}, exif...)
+	StripJPEGLocation(strippedEXIF)
+	strippedEXIF = strippedEXIF[2:]
+	jfif := jpegSegment(0xE0, []byte("JFIF\x00\x01\x01"))
+	jpegXMP := jpegSegment(0xE1, append([]byte("http://ns.adobe.com/xap/1.0/\x00"), xmp...))
+	jpegExtXMP := jpegSegment(0xE1, append([]byte("http://ns.adobe.com/xmp/extension/\x00"), xmp...))
+
+	badCRC := append([]byte{}, rawPNG...)
+	badCRC[pngMetaOffset-1] ^= 0xFF
+
+	tests := []struct {
+		name     string
+		mimeType string
+		data     []byte
+		want     []byte
+		wantErr  bool
+		// decode - natija standart decoder bilan o'qilishini tekshirish
+		decode func([]byte) error
+	}{
+		{
+			name:     "jpeg drops xmp and keeps cleaned exif",
+			mimeType: "image/jpeg",
+			data:     jpegFixture(jfif, exif, jpegXMP, jpegExtXMP),
+			want:     jpegFixture(jfif, strippedEXIF),
+		},
+		{
+			name:     "jpeg fill bytes before exif",
+			mimeType: "image/jpeg",
+			data:     insertAt(jpegFixture(exif, jpegXMP), 2, []byte{0xFF, 0xFF}),
+			want:     jpegFixture(strippedEXIF),
+		},
+		{
+			name:     "jpeg without metadata is unchanged",
+			mimeType: "image/jpeg",
+			data:     jpegFixture(jfif),
+			want:     jpegFixture(jfif),
+		},
+		{
+			name:     "jpeg segment length past end",
+			mimeType: "image/jpeg",
+			data:     []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x10, 0x00, 'h', 't'},
+			wantErr:  true,
+		},
+		{
+			name:     "jpeg segment length below minimum",
+			mimeType: "image/jpeg",
+			data:     []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x01, 0xFF, 0xDA},
+			wantErr:  true,
+		},
+		{
+			name:     "jpeg garbage between segments",
+			mimeType: "image/jpeg",
+			data:     []byte{0xFF, 0xD8, 0x00, 0x00, 0xFF, 0xDA},
+			wantErr:  true,
+		},
+		{
+			name:     "jpeg truncated before sos",
+			mimeType: "image/jpeg",
+			data:     append([]byte{0xFF, 0xD8}, jfif...),
+			wantErr:  true,
+		},
+		{
+			name:     "png drops exif and text chunks",
+			mimeType: "image/png",
+			data: insertAt(rawPNG, pngMetaOffset,
+				pngChunk("eXIf", tiffFixture{orientation: 1}.build()),
+				pngChunk("tEXt", []byte("Comment\x00taken at home")),
+				pngChunk("iTXt", append([]byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00"), xmp...)),
+				pngChunk("zTXt", []byte("Author\x00\x00x")),
+			),
+			want:   rawPNG,
+			decode: func(b []byte) error { _, err := png.Decode(bytes.NewReader(b)); return err },
+		},
+		{
+			name:     "png bad crc",
+			mimeType: "image/png",
+			data:     badCRC,
+			wantErr:  true,
+		},
+		{
+			name:     "png truncated chunk",
+			mimeType: "image/png",
+			data:     rawPNG[:40],
+			wantErr:  true,
+		},
+		{
+			name:     "png chunk length past end",
+			mimeType: "image/png",
+			data:     append(append([]byte{}, pngSignature...), 0x7F, 0xFF, 0xFF, 0xFF, 'I', 'H', 'D', 'R', 0, 0, 0, 0),
+			wantErr:  true,
+		},
+		{
+			name:     "png bad signature",
+			mimeType: "image/png",
+			data:     rawPNG[1:],
+			wantErr:  true,
+		},
+		{
+			name:     "webp drops exif and xmp and clears flags",
+			mimeType: "image/webp",
+			data:     webpFixture(vp8x(0x0C|0x10), vp8l, webpChunk("EXIF", []byte("GPS!!")), webpChunk("XMP ", xmp)),
+			want:     webpFixture(vp8x(0x10), vp8l),
+		},
+		{
+			name:     "webp odd final chunk without padding",
+			mimeType: "image/webp",
+			data:     webpFixture(webpChunk("EXIF", []byte("ab")), vp8l[:len(vp8l)-1]),
+			want:     webpFixture(vp8l[:len(vp8l)-1]),
+		},
+		{
+			name:     "webp chunk size past end",
+			mimeType: "image/webp",
+			data:     webpFixture(append([]byte("EXIF"), 0xFF, 0xFF, 0, 0, 'a')),
+			wantErr:  true,
+		},
+		{
+			name:     "webp truncated chunk header",
+			mimeType: "image/webp",
+			data:     webpFixture(vp8l, []byte("EXI")),
+			wantErr:  true,
+		},
+		{
+			name:     "webp not riff",
+			mimeType: "image/webp",
+			data:     []byte("RIFX\x00\x00\x00\x00WEBP"),
+			wantErr:  true,
+		},
+		{
+			name:     "gif drops xmp and comments and keeps looping",
+			mimeType: "image/gif",
+			data:     insertAt(rawGIF, gifMetaOffset, gifLoop, gifXMP, gifComment),
+			want:     insertAt(rawGIF, gifMetaOffset, gifLoop),
+			decode:   func(b []byte) error { _, err := gif.DecodeAll(bytes.NewReader(b)); return err },
+		},
+		{
+			name:     "gif without trailer",
+			mimeType: "image/gif",
+			data:     insertAt(rawGIF[:len(rawGIF)-1], gifMetaOffset, gifComment),
+			want:     rawGIF[:len(rawGIF)-1],
+		},
+		{
+			name:     "gif truncated sub-blocks",
+			mimeType: "image/gif",
+			data:     append(append([]byte{}, rawGIF[:gifMetaOffset]...), 0x21, 0xFE, 10, 'a'),
+			wantErr:  true,
+		},
+		{
+			name:     "gif global palette past end",
+			mimeType: "image/gif",
+			data:     []byte("GIF89a\x01\x00\x01\x00\x87\x00\x00"),
+			wantErr:  true,
+		},
+		{
+			name:     "gif unknown block",
+			mimeType: "image/gif",
+			data:     insertAt(rawGIF, gifMetaOffset, []byte{0x99}),
+			wantErr:  true,
+		},
+		{
+			name:     "unsupported type",
+			mimeType: "image/bmp",
+			data:     []byte("BM\x00\x00"),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StripMetadata(append([]byte{}, tt.data...), tt.mimeType)
+			if tt.wantErr {
+				if !errors.Is(err, ErrMetadataNotStripped) {
+					t.Fatalf("err = %v, want ErrMetadataNotStripped", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Fatalf("got  % x\nwant % x", got, tt.want)
+			}
+			if bytes.Contains(got, xmp) {
+				t.Fatal("xmp still present")
+			}
+			if tt.decode != nil {
+				if err := tt.decode(got); err != nil {
+					t.Fatalf("stripped image does not decode: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type Attachment struct {
+	ID          int64
+	ChatID      int64
+	UploaderID  int64
+	MessageID   *int64
+	StorageKey  string
+	FileName    string
+	MimeType    string
+	SizeBytes   int64
+	Width       *int
+	Height      *int
+	Placeholder *string
+	CreatedAt   string
+	Thumbnails  []AttachmentThumbnail
+}
+
+type AttachmentThumbnail struct {
+	AttachmentID int64
+	Size         string
+	StorageKey   string
+	Width        int
+	Height       int
+}
+
+const attachmentColumns = `id, chat_id, uploader_id, message_id, storage_key, file_name, mime_type, size_bytes,
+        width, height, placeholder, created_at`
+
+type AttachmentStorage struct {
+	db DBTX
+}
+
+func (s *AttachmentStorage) Create(ctx context.Context, a *Attachment) error {
+	query := `INSERT INTO attachments (chat_id, uploader_id, storage_key, file_name, mime_type, size_bytes, width, height, placeholder)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at`
+
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		a.ChatID,
+		a.UploaderID,
+		a.StorageKey,
+		a.FileName,
+		a.MimeType,
+		a.SizeBytes,
+		a.Width,
+		a.Height,
+		a.Placeholder,
+	).Scan(
+		&a.ID,
+		&a.CreatedAt,
+	)
+}
+
+func (s *AttachmentStorage) GetByID(ctx context.Context, id int64) (*Attachment, error) {
+	query := `
+        SELECT ` + attachmentColumns + `
+        FROM attachments
+        WHERE id = $1`
+
+	var a Attachment
+	err := s.db.QueryRowContext(ctx, query, id).Scan(attachmentDest(&a)...)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, SqlNotfound
+		default:
+			return nil, err
+		}
+	}
+
+	return &a, nil
+}
+
+// AttachToMessage - yuklangan, hali xabarga bog'lanmagan fayllarni xabarga bog'laydi.
+// Faqat shu user shu chatga yuklagan fayllar bog'lanadi.
+func (s *AttachmentStorage) AttachToMessage(ctx context.Context, ids []int64, messageID, uploaderID, chatID int64) ([]Attachment, error) {
+	query := `
+        UPDATE attachments
+        SET message_id = $1
+        WHERE id = ANY($2)
+          AND uploader_id = $3
+          AND chat_id = $4
+          AND message_id IS NULL
+        RETURNING ` + attachmentColumns
+
+	rows, err := s.db.QueryContext(ctx, query, messageID, pq.Array(ids), uploaderID, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments, err := scanAttachments(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withThumbnails(ctx, attachments)
+}
+
+func (s *AttachmentStorage) GetByMessageIDs(ctx context.Context, msgIDs []int64) (map[int64][]Attachment, error) {
+	result := make(map[int64][]Attachment)
+	if len(msgIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+        SELECT ` + attachmentColumns + `
+        FROM attachments
+        WHERE message_id = ANY($1)
+        ORDER BY id ASC`
+
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(msgIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments, err := scanAttachments(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err = s.withThumbnails(ctx, attachments)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range attachments {
+		result[*a.MessageID] = append(result[*a.MessageID], a)
+	}
+
+	return result, nil
+}
+
+// DeleteByMessage - xabarga bog'langan attachment va thumbnail'larni o'chirib, blob key'larini qaytaradi
+func (s *AttachmentStorage) DeleteByMessage(ctx context.Context, msgID int64) ([]string, error) {
+	return s.deleteWhere(ctx, "message_id = $1", msgID)
+}
+
+// DeleteByMessages - DeleteByMessage'ning bir nechta xabar uchun varianti (expired sweeper)
+func (s *AttachmentStorage) DeleteByMessages(ctx context.Context, msgIDs []int64) ([]string, error) {
+	if len(msgIDs) == 0 {
+		return nil, nil
+	}
+	return s.deleteWhere(ctx, "message_id = ANY($1::bigint[])", pq.Array(msgIDs))
+}
+
+// DeleteByChat - chatdagi barcha attachment'larni (xabarga bog'lanmaganlarini ham) o'chirib, blob key'larini qaytaradi
+func (s *AttachmentStorage) DeleteByChat(ctx context.Context, chatID int64) ([]string, error) {
+	return s.deleteWhere(ctx, "chat_id = $1", chatID)
+}
+
+// DeleteOrphans - olderThan'dan oldin yuklangan, lekin xabarga bog'lanmagan fayllarni o'chiradi.
+// Boshqa instance sweeper'i band qilgan qatorlar o'tkazib yuboriladi.
+func (s *AttachmentStorage) DeleteOrphans(ctx context.Context, olderThan time.Duration, limit int) ([]string, error) {
+	query := `
+        SELECT id
+        FROM attachments
+        WHERE message_id IS NULL
+          AND created_at < NOW() - make_interval(secs => $1)
+        ORDER BY id ASC
+        LIMIT $2
+        FOR UPDATE SKIP LOCKED`
+
+	rows, err := s.db.QueryContext(ctx, query, olderThan.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return s.deleteWhere(ctx, "id = ANY($1::bigint[])", pq.Array(ids))
+}
+
+// deleteWhere - shartga mos attachment'larni thumbnail'lari bilan o'chiradi.
+// Thumbnail'lar oldin o'chiriladi: CASCADE ularning key'larini qaytarmaydi.
+func (s *AttachmentStorage) deleteWhere(ctx context.Context, cond string, arg any) ([]string, error) {
+	thumbsQuery := `
+        DELETE FROM attachment_thumbnails
+        WHERE attachment_id IN (SELECT id FROM attachments WHERE ` + cond + `)
+        RETURNING storage_key`
+
+	keys, err := s.deleteReturningKeys(ctx, thumbsQuery, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `DELETE FROM attachments WHERE ` + cond + ` RETURNING storage_key`
+
+	attachmentKeys, err := s.deleteReturningKeys(ctx, query, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(keys, attachmentKeys...), nil
+}
+
+func (s *AttachmentStorage) deleteReturningKeys(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (s *AttachmentStorage) CreateThumbnail(ctx context.Context, t *AttachmentThumbnail) error {
+	query := `INSERT INTO attachment_thumbnails (attachment_id, size, storage_key, width, height)
+	VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := s.db.ExecContext(ctx, query, t.AttachmentID, t.Size, t.StorageKey, t.Width, t.Height)
+	return err
+}
+
+func (s *AttachmentStorage) GetThumbnail(ctx context.Context, attachmentID int64, size string) (*AttachmentThumbnail, error) {
+	query := `
+        SELECT attachment_id, size, storage_key, width, height
+        FROM attachment_thumbnails
+        WHERE attachment_id = $1 AND size = $2`
+
+	var t AttachmentThumbnail
+	err := s.db.QueryRowContext(ctx, query, attachmentID, size).Scan(
+		&t.AttachmentID, &t.Size, &t.StorageKey, &t.Width, &t.Height,
+	)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, SqlNotfound
+		default:
+			return nil, err
+		}
+	}
+
+	return &t, nil
+}
+
+// withThumbnails - attachment'larga ularning thumbnail'larini bitta so'rov bilan biriktiradi
+func (s *AttachmentStorage) withThumbnails(ctx context.Context, attachments []Attachment) ([]Attachment, error) {
+	if len(attachments) == 0 {
+		return attachments, nil
+	}
+
+	ids := make([]int64, len(attachments))
+	for i, a := range attachments {
+		ids[i] = a.ID
+	}
+
+	query := `
+        SELECT attachment_id, size, storage_key, width, height
+        FROM attachment_thumbnails
+        WHERE attachment_id = ANY($1)
+        ORDER BY attachment_id, width ASC`
+
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	thumbnails := make(map[int64][]AttachmentThumbnail)
+	for rows.Next() {
+		var t AttachmentThumbnail
+		if err := rows.Scan(&t.AttachmentID, &t.Size, &t.StorageKey, &t.Width, &t.Height); err != nil {
+			return nil, err
+		}
+		thumbnails[t.AttachmentID] = append(thumbnails[t.AttachmentID], t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range attachments {
+		attachments[i].Thumbnails = thumbnails[attachments[i].ID]
+	}
+
+	return attachments, nil
+}
+
+func scanAttachments(rows *sql.Rows) ([]Attachment, error) {
+	var attachments []Attachment
+	for rows.Next() {
+		var a Attachment
+		if err := rows.Scan(attachmentDest(&a)...); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+func attachmentDest(a *Attachment) []any {
+	return []any{
+		&a.ID, &a.ChatID, &a.UploaderID, &a.MessageID, &a.StorageKey, &a.FileName,
+		&a.MimeType, &a.SizeBytes, &a.Width, &a.Height, &a.Placeholder, &a.CreatedAt,
+	}
+}
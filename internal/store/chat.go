@@ -7,20 +7,26 @@ import (
 )
 
 type Chat struct {
-	ID        int64  `json:"id"`
-	ChatType  string `json:"chat_type"`
-	CreatedAt string `json:"created_at"`
+	ID         int64  `json:"id"`
+	ChatType   string `json:"chat_type"`
+	CreatedAt  string `json:"created_at"`
+	MessageTTL *int   `json:"message_ttl_seconds"`
 }
 
 type ChatInfo struct {
-	ChatID        int64  `json:"chat_id"`
-	ChatType      string `json:"chat_type"`
-	ChatName      string `json:"chat_name"`
-	UserRole      string `json:"user_role"`
-	JoinedAt      string `json:"joined_at"`
-	LastMessage   string `json:"last_message"`
-	LastMessageAt string `json:"last_message_at"`
-	UnreadCount   int    `json:"unread_count"`
+	ChatID         int64  `json:"chat_id"`
+	ChatType       string `json:"chat_type"`
+	ChatName       string `json:"chat_name"`
+	UserRole       string `json:"user_role"`
+	JoinedAt       string `json:"joined_at"`
+	LastMessage    string `json:"last_message"`
+	LastMessageAt  string `json:"last_message_at"`
+	UnreadCount    int    `json:"unread_count"`
+	UnreadMentions int    `json:"unread_mentions"`
+	MessageTTL     *int   `json:"message_ttl_seconds"`
+	// PeerID va Presence faqat private chatlar uchun (suhbatdosh)
+	PeerID   *int64    `json:"peer_id,omitempty"`
+	Presence *Presence `json:"presence,omitempty"`
 }
 
 type Chatcheck struct {
@@ -50,7 +56,7 @@ func (s *Chatstorage) Createchat(ctx context.Context, chat *Chat) (int64, error)
 }
 
 func (s *Chatstorage) GetByID(ctx context.Context, ChatID int64) (*Chat, error) {
-	query := `SELECT id, chat_type, created_at FROM chats WHERE id = $1`
+	query := `SELECT id, chat_type, created_at, message_ttl_seconds FROM chats WHERE id = $1`
 
 	chat := &Chat{}
 
@@ -62,6 +68,7 @@ func (s *Chatstorage) GetByID(ctx context.Context, ChatID int64) (*Chat, error)
 		&chat.ID,
 		&chat.ChatType,
 		&chat.CreatedAt,
+		&chat.MessageTTL,
 	)
 
 	if err != nil {
@@ -91,21 +98,49 @@ func (s *Chatstorage) GetChatByUserID(ctx context.Context, id int64, searchTerm
         (SELECT COUNT(*)
          FROM messages m2
          WHERE m2.chat_id = c.id
+           AND m2.id > cm.last_read_message_id
            AND m2.sender_id != $1
+           AND m2.deleted_at IS NULL
+           AND (m2.expires_at IS NULL OR m2.expires_at > NOW())
            AND NOT EXISTS (
                SELECT 1
-               FROM message_reads mr
-               WHERE mr.message_id = m2.id
-                 AND mr.user_id = $1
-           )) AS unread_count
+               FROM message_hidden mh2
+               WHERE mh2.message_id = m2.id
+                 AND mh2.user_id = $1
+           )) AS unread_count,
+        (SELECT COUNT(*)
+         FROM message_mentions mm
+         JOIN messages m3 ON m3.id = mm.message_id
+         WHERE mm.user_id = $1
+           AND m3.chat_id = c.id
+           AND m3.id > cm.last_read_message_id
+           AND m3.deleted_at IS NULL
+           AND (m3.expires_at IS NULL OR m3.expires_at > NOW())
+           AND NOT EXISTS (
+               SELECT 1
+               FROM message_hidden mh
+               WHERE mh.message_id = m3.id
+                 AND mh.user_id = $1
+           )) AS unread_mentions,
+        c.message_ttl_seconds,
+        peer.id AS peer_id,
+        CASE WHEN peer.hide_last_seen THEN NULL ELSE peer.last_seen_at END AS peer_last_seen_at
     FROM chat_members cm
     JOIN chats c ON cm.chat_id = c.id
     LEFT JOIN group_info gi ON c.id = gi.chat_id
     LEFT JOIN (
         SELECT DISTINCT ON (chat_id) chat_id, message_text, created_at
         FROM messages
+        WHERE expires_at IS NULL OR expires_at > NOW()
         ORDER BY chat_id, created_at DESC
     ) m ON m.chat_id = c.id
+    LEFT JOIN LATERAL (
+        SELECT u3.id, u3.last_seen_at, u3.hide_last_seen
+        FROM chat_members cm4
+        JOIN users u3 ON u3.id = cm4.user_id
+        WHERE c.chat_type = 'private' AND cm4.chat_id = c.id AND cm4.user_id != $1
+        LIMIT 1
+    ) peer ON TRUE
     WHERE cm.user_id = $1 
       AND (
           $2 = '' 
@@ -128,6 +163,7 @@ func (s *Chatstorage) GetChatByUserID(ctx context.Context, id int64, searchTerm
 	for rows.Next() {
 		var c ChatInfo
 		var lastMsgAt *time.Time
+		var peerLastSeen *string
 
 		err := rows.Scan(
 			&c.ChatID,
@@ -138,6 +174,10 @@ func (s *Chatstorage) GetChatByUserID(ctx context.Context, id int64, searchTerm
 			&c.LastMessage,
 			&lastMsgAt,
 			&c.UnreadCount,
+			&c.UnreadMentions,
+			&c.MessageTTL,
+			&c.PeerID,
+			&peerLastSeen,
 		)
 		if err != nil {
 			return nil, err
@@ -147,6 +187,10 @@ func (s *Chatstorage) GetChatByUserID(ctx context.Context, id int64, searchTerm
 			c.LastMessageAt = lastMsgAt.Format("2006-01-02 15:04:05")
 		}
 
+		if c.PeerID != nil {
+			c.Presence = &Presence{LastSeenAt: peerLastSeen}
+		}
+
 		chats = append(chats, &c)
 	}
 	if err := rows.Err(); err != nil {
@@ -210,3 +254,23 @@ func (s *Chatstorage) Delete(ctx context.Context, chatID int) error {
 
 	return nil
 }
+
+// SetMessageTTL - yangi xabarlar uchun yashash muddati, nil bo'lsa o'chiriladi
+func (s *Chatstorage) SetMessageTTL(ctx context.Context, chatID int64, ttlSeconds *int) error {
+	query := `UPDATE chats SET message_ttl_seconds = $1 WHERE id = $2`
+
+	result, err := s.db.ExecContext(ctx, query, ttlSeconds, chatID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return SqlNotfound
+	}
+
+	return nil
+}
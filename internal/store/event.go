@@ -0,0 +1,115 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Event - user'ning event oqimidagi bitta yozuv. Payload seq'siz saqlanadi.
+type Event struct {
+	Seq       int64
+	EventType string
+	Payload   []byte
+	CreatedAt string
+}
+
+type EventStorage struct {
+	db DBTX
+}
+
+// Append - event'ni har bir user oqimiga yozadi va har biriga berilgan seq raqamini qaytaradi.
+// Seq user_event_seqs qatorini yangilash orqali ajratiladi, shuning uchun parallel yozuvlarda ham takrorlanmaydi.
+// Qatorlar user_id tartibida lock qilinadi: kesishgan userlarga parallel yozuvlar deadlock'ka tushmaydi.
+func (s *EventStorage) Append(ctx context.Context, userIDs []int64, eventType string, payload []byte) (map[int64]int64, error) {
+	seqs := make(map[int64]int64, len(userIDs))
+	if len(userIDs) == 0 {
+		return seqs, nil
+	}
+
+	query := `
+        WITH bumped AS (
+            INSERT INTO user_event_seqs (user_id, last_seq)
+            SELECT DISTINCT user_id, 1 FROM unnest($1::bigint[]) AS user_id
+            ORDER BY user_id
+            ON CONFLICT (user_id) DO UPDATE SET last_seq = user_event_seqs.last_seq + 1
+            RETURNING user_id, last_seq
+        )
+        INSERT INTO user_events (user_id, seq, event_type, payload)
+        SELECT user_id, last_seq, $2, $3 FROM bumped
+        RETURNING user_id, seq`
+
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(userIDs), eventType, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID, seq int64
+		if err := rows.Scan(&userID, &seq); err != nil {
+			return nil, err
+		}
+		seqs[userID] = seq
+	}
+
+	return seqs, rows.Err()
+}
+
+// Since - since'dan keyingi event'larni seq tartibida qaytaradi
+func (s *EventStorage) Since(ctx context.Context, userID, since int64, limit int) ([]Event, error) {
+	query := `
+        SELECT seq, event_type, payload, created_at
+        FROM user_events
+        WHERE user_id = $1 AND seq > $2
+        ORDER BY seq ASC
+        LIMIT $3`
+
+	rows, err := s.db.QueryContext(ctx, query, userID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.Seq, &e.EventType, &e.Payload, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// Bounds - user oqimidagi saqlanib qolgan eng kichik seq (bo'lmasa 0) va oxirgi berilgan seq
+func (s *EventStorage) Bounds(ctx context.Context, userID int64) (int64, int64, error) {
+	query := `
+        SELECT
+            COALESCE((SELECT MIN(seq) FROM user_events WHERE user_id = $1), 0),
+            COALESCE((SELECT last_seq FROM user_event_seqs WHERE user_id = $1), 0)`
+
+	var minSeq, lastSeq int64
+	err := s.db.QueryRowContext(ctx, query, userID).Scan(&minSeq, &lastSeq)
+	return minSeq, lastSeq, err
+}
+
+// Prune - retention'dan eski event'larni o'chiradi. Seq hisoblagichlari saqlanadi.
+func (s *EventStorage) Prune(ctx context.Context, retention time.Duration, limit int) (int64, error) {
+	query := `
+        DELETE FROM user_events
+        WHERE ctid IN (
+            SELECT ctid FROM user_events
+            WHERE created_at < NOW() - make_interval(secs => $1)
+            LIMIT $2
+        )`
+
+	res, err := s.db.ExecContext(ctx, query, retention.Seconds(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,134 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+type Mention struct {
+	MessageID int64
+	UserID    int64
+	Username  string
+}
+
+type MentionStorage struct {
+	db DBTX
+}
+
+// ResolveMembers - username'larni (katta-kichik harfga qaramay) chat a'zolari orasidan qidiradi
+func (s *MentionStorage) ResolveMembers(ctx context.Context, chatID int64, usernames []string) ([]Mention, error) {
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+
+	query := `
+        SELECT u.id, u.username
+        FROM chat_members cm
+        JOIN users u ON u.id = cm.user_id
+        WHERE cm.chat_id = $1
+          AND LOWER(u.username) = ANY($2)`
+
+	rows, err := s.db.QueryContext(ctx, query, chatID, pq.Array(usernames))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mentions []Mention
+	for rows.Next() {
+		var m Mention
+		if err := rows.Scan(&m.UserID, &m.Username); err != nil {
+			return nil, err
+		}
+		mentions = append(mentions, m)
+	}
+
+	return mentions, rows.Err()
+}
+
+func (s *MentionStorage) Create(ctx context.Context, msgID int64, userIDs []int64) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	query := `
+        INSERT INTO message_mentions (message_id, user_id)
+        SELECT $1, unnest($2::bigint[])
+        ON CONFLICT (message_id, user_id) DO NOTHING`
+
+	_, err := s.db.ExecContext(ctx, query, msgID, pq.Array(userIDs))
+	return err
+}
+
+func (s *MentionStorage) DeleteByMessage(ctx context.Context, msgID int64) error {
+	query := `DELETE FROM message_mentions WHERE message_id = $1`
+
+	_, err := s.db.ExecContext(ctx, query, msgID)
+	return err
+}
+
+func (s *MentionStorage) GetByMessageIDs(ctx context.Context, msgIDs []int64) (map[int64][]Mention, error) {
+	result := make(map[int64][]Mention)
+	if len(msgIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+        SELECT mm.message_id, mm.user_id, u.username
+        FROM message_mentions mm
+        JOIN users u ON u.id = mm.user_id
+        WHERE mm.message_id = ANY($1)
+        ORDER BY mm.message_id, u.username`
+
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(msgIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m Mention
+		if err := rows.Scan(&m.MessageID, &m.UserID, &m.Username); err != nil {
+			return nil, err
+		}
+		result[m.MessageID] = append(result[m.MessageID], m)
+	}
+
+	return result, rows.Err()
+}
+
+// NextUnread - afterID dan keyingi, user hali o'qimagan birinchi mention xabarining ID sini qaytaradi
+func (s *MentionStorage) NextUnread(ctx context.Context, chatID, userID, afterID int64) (int64, error) {
+	query := `
+        SELECT m.id
+        FROM message_mentions mm
+        JOIN messages m ON m.id = mm.message_id
+        JOIN chat_members cm ON cm.chat_id = m.chat_id AND cm.user_id = mm.user_id
+        WHERE mm.user_id = $2
+          AND m.chat_id = $1
+          AND m.id > $3
+          AND m.id > cm.last_read_message_id
+          AND m.deleted_at IS NULL
+          AND (m.expires_at IS NULL OR m.expires_at > NOW())
+          AND NOT EXISTS (
+              SELECT 1 FROM message_hidden mh
+              WHERE mh.message_id = m.id AND mh.user_id = $2
+          )
+        ORDER BY m.id ASC
+        LIMIT 1`
+
+	var msgID int64
+	err := s.db.QueryRowContext(ctx, query, chatID, userID, afterID).Scan(&msgID)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return 0, SqlNotfound
+		default:
+			return 0, err
+		}
+	}
+
+	return msgID, nil
+}
@@ -3,6 +3,8 @@ package store
 import (
 	"context"
 	"database/sql"
+
+	"github.com/lib/pq"
 )
 
 type Message struct {
@@ -15,6 +17,17 @@ type Message struct {
 	UpdatedAt   string
 	SenderName  string
 	ChatName    string
+	ReplyToID   *int64
+	EditedAt    *string
+	DeletedAt   *string
+}
+
+// MessageRevision - xabarning tahrirdan oldingi matni
+type MessageRevision struct {
+	ID          int64
+	MessageID   int64
+	MessageText string
+	CreatedAt   string
 }
 
 type MessageStorage struct {
@@ -24,9 +37,15 @@ type MessageStorage struct {
 // create
 func (s *MessageStorage) Create(ctx context.Context, msg *Message) (Message, error) {
 	query := `WITH inserted_msg AS (
-    INSERT INTO messages (chat_id, sender_id, message_text) 
-    VALUES ($1, $2, $3) 
-    RETURNING id, chat_id, sender_id, message_text, is_read, created_at, updated_at
+    INSERT INTO messages (chat_id, sender_id, message_text, reply_to_message_id, expires_at) 
+    SELECT $1, $2, $3, $4,
+           CASE 
+               WHEN ch.message_ttl_seconds IS NULL THEN NULL
+               ELSE NOW() + make_interval(secs => ch.message_ttl_seconds)
+           END
+    FROM chats ch
+    WHERE ch.id = $1
+    RETURNING id, chat_id, sender_id, message_text, is_read, created_at, updated_at, reply_to_message_id
 )
 SELECT 
     m.id, 
@@ -36,6 +55,7 @@ SELECT
     m.is_read, 
     m.created_at, 
     m.updated_at,
+    m.reply_to_message_id,
     u.username AS sender_name,
     CASE 
         WHEN c.chat_type = 'group' THEN gi.group_name
@@ -53,10 +73,10 @@ JOIN chats c ON m.chat_id = c.id
 LEFT JOIN group_info gi ON c.id = gi.chat_id;`
 
 	var result Message
-	err := s.db.QueryRowContext(ctx, query, msg.ChatID, msg.SenderID, msg.MessageText).
+	err := s.db.QueryRowContext(ctx, query, msg.ChatID, msg.SenderID, msg.MessageText, msg.ReplyToID).
 		Scan(
 			&result.ID, &result.ChatID, &result.SenderID, &result.MessageText,
-			&result.IsRead, &result.CreatedAt, &result.UpdatedAt,
+			&result.IsRead, &result.CreatedAt, &result.UpdatedAt, &result.ReplyToID,
 			&result.SenderName, &result.ChatName,
 		)
 
@@ -75,17 +95,29 @@ type MessageDetail struct {
 	SenderName string
 	CreatedAt  string
 	IsRead     bool
+	ReplyTo    *ReplyPreview
+	ReplyCount int
+	EditedAt   *string
+	DeletedAt  *string
+}
+
+// ReplyPreview - javob berilgan xabarning qisqa ko'rinishi
+type ReplyPreview struct {
+	ID         int64
+	SenderID   int64
+	SenderName string
+	Content    string
 }
 
 func (s *MessageStorage) GetByID(ctx context.Context, id int64) (*Message, error) {
 	query := `
-        SELECT id, chat_id, sender_id, message_text, is_read, created_at, updated_at 
+        SELECT id, chat_id, sender_id, message_text, is_read, created_at, updated_at, reply_to_message_id, edited_at, deleted_at 
         FROM messages 
         WHERE id = $1`
 
 	var m Message
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
-		&m.ID, &m.ChatID, &m.SenderID, &m.MessageText, &m.IsRead, &m.CreatedAt, &m.UpdatedAt,
+		&m.ID, &m.ChatID, &m.SenderID, &m.MessageText, &m.IsRead, &m.CreatedAt, &m.UpdatedAt, &m.ReplyToID, &m.EditedAt, &m.DeletedAt,
 	)
 
 	if err != nil {
@@ -100,8 +132,25 @@ func (s *MessageStorage) GetByID(ctx context.Context, id int64) (*Message, error
 	return &m, nil
 }
 
-func (s *MessageStorage) GetMessages(ctx context.Context, chatID int64) ([]MessageDetail, error) {
-	query := `
+type MessagePage struct {
+	Messages   []MessageDetail
+	PrevCursor *int64
+	NextCursor *int64
+}
+
+// visibleMessage - alias xabari viewer'ga ($2) ko'rinadimi: muddati o'tmagan va "faqat men uchun"
+// o'chirilmagan. Xabarlar sahifasi, prev/next cursor'lar va reply_count bir xil shartdan foydalanadi.
+func visibleMessage(alias string) string {
+	return `(` + alias + `.expires_at IS NULL OR ` + alias + `.expires_at > NOW())
+          AND NOT EXISTS (
+                  SELECT 1
+                  FROM message_hidden mh
+                  WHERE mh.message_id = ` + alias + `.id
+                    AND mh.user_id = $2
+              )`
+}
+
+var messageDetailQuery = `
         SELECT m.id,
                m.message_text,
                m.sender_id,
@@ -109,16 +158,180 @@ func (s *MessageStorage) GetMessages(ctx context.Context, chatID int64) ([]Messa
                m.created_at,
                EXISTS (
                    SELECT 1
-                   FROM message_reads mr
-                   WHERE mr.message_id = m.id
-                     AND mr.user_id <> m.sender_id
-               ) AS is_read
+                   FROM chat_members rcm
+                   WHERE rcm.chat_id = m.chat_id
+                     AND rcm.user_id <> m.sender_id
+                     AND rcm.last_read_message_id >= m.id
+               ) AS is_read,
+               rm.id,
+               rm.sender_id,
+               ru.username,
+               rm.message_text,
+               (
+                   -- viewer thread'da ko'ra oladigan javoblar, GetThread'dagi kabi
+                   SELECT COUNT(*)
+                   FROM messages r
+                   WHERE r.reply_to_message_id = m.id
+                     AND ` + visibleMessage("r") + `
+               ) AS reply_count,
+               m.edited_at,
+               m.deleted_at
         FROM messages m
         JOIN users u ON m.sender_id = u.id
-        WHERE m.chat_id = $1
-        ORDER BY m.created_at ASC`
+        LEFT JOIN messages rm ON rm.id = m.reply_to_message_id
+              AND (rm.expires_at IS NULL OR rm.expires_at > NOW())
+        LEFT JOIN users ru ON ru.id = rm.sender_id
+        WHERE ` + visibleMessage("m") + `
+          AND `
+
+// GetMessages - keyset pagination: before/after/around xabar ID bo'yicha,
+// natija har doim eskidan yangiga tartiblangan bo'ladi.
+// viewerID "faqat men uchun" o'chirilgan xabarlarni chiqarib tashlash uchun kerak.
+func (s *MessageStorage) GetMessages(ctx context.Context, chatID, viewerID int64, cq *CursorQuery) (*MessagePage, error) {
+	return s.getMessagePage(ctx, "m.chat_id = $1", chatID, viewerID, cq)
+}
+
+// GetThread - root xabarga berilgan javoblar, GetMessages bilan bir xil pagination
+func (s *MessageStorage) GetThread(ctx context.Context, rootID, viewerID int64, cq *CursorQuery) (*MessagePage, error) {
+	return s.getMessagePage(ctx, "m.reply_to_message_id = $1", rootID, viewerID, cq)
+}
+
+func (s *MessageStorage) getMessagePage(ctx context.Context, filter string, key, viewerID int64, cq *CursorQuery) (*MessagePage, error) {
+	page := &MessagePage{}
+	base := messageDetailQuery + filter
+
+	switch {
+	case cq.Around > 0:
+		olderLimit := cq.Limit / 2
+		newerLimit := cq.Limit - olderLimit
+
+		older, err := s.queryMessageDetails(ctx, base+`
+          AND m.id < $3
+        ORDER BY m.id DESC
+        LIMIT $4`, key, viewerID, cq.Around, olderLimit+1)
+		if err != nil {
+			return nil, err
+		}
+
+		newer, err := s.queryMessageDetails(ctx, base+`
+          AND m.id >= $3
+        ORDER BY m.id ASC
+        LIMIT $4`, key, viewerID, cq.Around, newerLimit+1)
+		if err != nil {
+			return nil, err
+		}
+
+		hasOlder := len(older) > olderLimit
+		if hasOlder {
+			older = older[:olderLimit]
+		}
+		hasNewer := len(newer) > newerLimit
+		if hasNewer {
+			newer = newer[:newerLimit]
+		}
+
+		reverseMessages(older)
+		page.Messages = append(older, newer...)
+		page.setCursors(hasOlder, hasNewer)
+
+	case cq.After > 0:
+		newer, err := s.queryMessageDetails(ctx, base+`
+          AND m.id > $3
+        ORDER BY m.id ASC
+        LIMIT $4`, key, viewerID, cq.After, cq.Limit+1)
+		if err != nil {
+			return nil, err
+		}
 
-	rows, err := s.db.QueryContext(ctx, query, chatID)
+		hasNewer := len(newer) > cq.Limit
+		if hasNewer {
+			newer = newer[:cq.Limit]
+		}
+
+		hasOlder, err := s.messageExists(ctx, filter+" AND m.id <= $3", key, viewerID, cq.After)
+		if err != nil {
+			return nil, err
+		}
+
+		page.Messages = newer
+		page.setCursors(hasOlder, hasNewer)
+
+	case cq.Before > 0:
+		older, err := s.queryMessageDetails(ctx, base+`
+          AND m.id < $3
+        ORDER BY m.id DESC
+        LIMIT $4`, key, viewerID, cq.Before, cq.Limit+1)
+		if err != nil {
+			return nil, err
+		}
+
+		hasOlder := len(older) > cq.Limit
+		if hasOlder {
+			older = older[:cq.Limit]
+		}
+
+		hasNewer, err := s.messageExists(ctx, filter+" AND m.id >= $3", key, viewerID, cq.Before)
+		if err != nil {
+			return nil, err
+		}
+
+		reverseMessages(older)
+		page.Messages = older
+		page.setCursors(hasOlder, hasNewer)
+
+	default:
+		latest, err := s.queryMessageDetails(ctx, base+`
+        ORDER BY m.id DESC
+        LIMIT $3`, key, viewerID, cq.Limit+1)
+		if err != nil {
+			return nil, err
+		}
+
+		hasOlder := len(latest) > cq.Limit
+		if hasOlder {
+			latest = latest[:cq.Limit]
+		}
+
+		reverseMessages(latest)
+		page.Messages = latest
+		page.setCursors(hasOlder, false)
+	}
+
+	return page, nil
+}
+
+// messageExists - cursor'dan narigi tomonda viewer ko'ra oladigan xabar bormi (prev/next cursor uchun)
+func (s *MessageStorage) messageExists(ctx context.Context, filter string, key, viewerID, cursor int64) (bool, error) {
+	query := `
+        SELECT EXISTS (
+            SELECT 1
+            FROM messages m
+            WHERE ` + visibleMessage("m") + `
+              AND ` + filter + `
+        )`
+
+	var exists bool
+	err := s.db.QueryRowContext(ctx, query, key, viewerID, cursor).Scan(&exists)
+	return exists, err
+}
+
+func (p *MessagePage) setCursors(hasOlder, hasNewer bool) {
+	if len(p.Messages) == 0 {
+		return
+	}
+
+	if hasOlder {
+		prev := p.Messages[0].ID
+		p.PrevCursor = &prev
+	}
+	if hasNewer {
+		next := p.Messages[len(p.Messages)-1].ID
+		p.NextCursor = &next
+	}
+}
+
+func (s *MessageStorage) queryMessageDetails(ctx context.Context, query string, args ...any) ([]MessageDetail, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -127,9 +340,23 @@ func (s *MessageStorage) GetMessages(ctx context.Context, chatID int64) ([]Messa
 	var messages []MessageDetail
 	for rows.Next() {
 		var msg MessageDetail
-		if err := rows.Scan(&msg.ID, &msg.Content, &msg.SenderID, &msg.SenderName, &msg.CreatedAt, &msg.IsRead); err != nil {
+		var replyID, replySenderID sql.NullInt64
+		var replySenderName, replyContent sql.NullString
+		if err := rows.Scan(
+			&msg.ID, &msg.Content, &msg.SenderID, &msg.SenderName, &msg.CreatedAt, &msg.IsRead,
+			&replyID, &replySenderID, &replySenderName, &replyContent, &msg.ReplyCount,
+			&msg.EditedAt, &msg.DeletedAt,
+		); err != nil {
 			return nil, err
 		}
+		if replyID.Valid {
+			msg.ReplyTo = &ReplyPreview{
+				ID:         replyID.Int64,
+				SenderID:   replySenderID.Int64,
+				SenderName: replySenderName.String,
+				Content:    replyContent.String,
+			}
+		}
 		messages = append(messages, msg)
 	}
 	if err := rows.Err(); err != nil {
@@ -139,24 +366,71 @@ func (s *MessageStorage) GetMessages(ctx context.Context, chatID int64) ([]Messa
 	return messages, nil
 }
 
-func (s *MessageStorage) MarkAsRead(ctx context.Context, chatID, userID int64) error {
+func reverseMessages(messages []MessageDetail) {
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+}
+
+// MarkAsRead - user'ning o'qish cursor'ini chatdagi eng oxirgi xabarga suradi
+// va shu xabar ID sini qaytaradi (xabar bo'lmasa 0). Cursor faqat oldinga siljiydi,
+// har siljish read_cursor_history'ga yoziladi.
+func (s *MessageStorage) MarkAsRead(ctx context.Context, chatID, userID int64) (int64, error) {
 	query := `
-        INSERT INTO message_reads (message_id, user_id, read_at)
-        SELECT m.id, $2, NOW()
-        FROM messages m
-        WHERE m.chat_id = $1
-          AND m.sender_id <> $2
-        ON CONFLICT (message_id, user_id) DO NOTHING`
+        WITH latest AS (
+            SELECT COALESCE(MAX(id), 0) AS id FROM messages WHERE chat_id = $1
+        ), moved AS (
+            UPDATE chat_members cm
+            SET last_read_message_id = latest.id,
+                last_read_at = NOW()
+            FROM latest
+            WHERE cm.chat_id = $1
+              AND cm.user_id = $2
+              AND cm.last_read_message_id < latest.id
+            RETURNING cm.last_read_message_id
+        ), history AS (
+            INSERT INTO read_cursor_history (chat_id, user_id, last_read_message_id)
+            SELECT $1, $2, last_read_message_id FROM moved
+        )
+        SELECT id FROM latest`
 
-	_, err := s.db.ExecContext(ctx, query, chatID, userID)
-	return err
+	var lastID int64
+	if err := s.db.QueryRowContext(ctx, query, chatID, userID).Scan(&lastID); err != nil {
+		return 0, err
+	}
+
+	return lastID, nil
 }
 
 // Update
-func (s *MessageStorage) Update(ctx context.Context, msgID, userID int64, newText string) error {
-	query := `UPDATE messages SET message_text = $1, updated_at = NOW() 
-              WHERE id = $2 AND sender_id = $3`
-	result, err := s.db.ExecContext(ctx, query, newText, msgID, userID)
+func (s *MessageStorage) Update(ctx context.Context, msgID, userID int64, newText string) (string, error) {
+	query := `UPDATE messages SET message_text = $1, edited_at = NOW(), updated_at = NOW() 
+              WHERE id = $2 AND sender_id = $3 AND deleted_at IS NULL
+              RETURNING edited_at`
+
+	var editedAt string
+	err := s.db.QueryRowContext(ctx, query, newText, msgID, userID).Scan(&editedAt)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return "", SqlNotfound
+		default:
+			return "", err
+		}
+	}
+
+	return editedAt, nil
+}
+
+// CreateRevision - joriy matnni tahrirlashdan oldin tarixga yozadi
+func (s *MessageStorage) CreateRevision(ctx context.Context, msgID, userID int64) error {
+	query := `INSERT INTO message_revisions (message_id, message_text)
+              SELECT id, message_text
+              FROM messages
+              WHERE id = $1 AND sender_id = $2 AND deleted_at IS NULL
+              FOR UPDATE`
+
+	result, err := s.db.ExecContext(ctx, query, msgID, userID)
 	if err != nil {
 		return err
 	}
@@ -172,10 +446,39 @@ func (s *MessageStorage) Update(ctx context.Context, msgID, userID int64, newTex
 	return nil
 }
 
-// Delete
-func (s *MessageStorage) Delete(ctx context.Context, msgID, userID int64) error {
-	query := `DELETE FROM messages WHERE id = $1 AND sender_id = $2`
-	result, err := s.db.ExecContext(ctx, query, msgID, userID)
+func (s *MessageStorage) GetRevisions(ctx context.Context, msgID int64) ([]MessageRevision, error) {
+	query := `
+        SELECT id, message_id, message_text, created_at
+        FROM message_revisions
+        WHERE message_id = $1
+        ORDER BY id ASC`
+
+	rows, err := s.db.QueryContext(ctx, query, msgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []MessageRevision
+	for rows.Next() {
+		var rev MessageRevision
+		if err := rows.Scan(&rev.ID, &rev.MessageID, &rev.MessageText, &rev.CreatedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// Delete - "hamma uchun o'chirish": matn tozalanadi, timeline'da tombstone qoladi
+func (s *MessageStorage) Delete(ctx context.Context, msgID, deletedBy int64) error {
+	query := `UPDATE messages SET message_text = '', deleted_at = NOW(), deleted_by = $2 
+              WHERE id = $1 AND deleted_at IS NULL`
+	result, err := s.db.ExecContext(ctx, query, msgID, deletedBy)
 	if err != nil {
 		return err
 	}
@@ -190,3 +493,72 @@ func (s *MessageStorage) Delete(ctx context.Context, msgID, userID int64) error
 
 	return nil
 }
+
+func (s *MessageStorage) DeleteRevisions(ctx context.Context, msgID int64) error {
+	query := `DELETE FROM message_revisions WHERE message_id = $1`
+
+	_, err := s.db.ExecContext(ctx, query, msgID)
+	return err
+}
+
+// ExpiredMessage - sweeper o'chirgan xabar
+type ExpiredMessage struct {
+	ID     int64
+	ChatID int64
+}
+
+// LockExpired - muddati o'tgan xabarlarni o'chirish uchun band qiladi. Boshqa instance
+// sweeper'i band qilganlari o'tkazib yuboriladi. Transaction ichida chaqirilishi kerak.
+func (s *MessageStorage) LockExpired(ctx context.Context, limit int) ([]ExpiredMessage, error) {
+	query := `
+        SELECT id, chat_id
+        FROM messages
+        WHERE expires_at IS NOT NULL AND expires_at <= NOW()
+        ORDER BY expires_at ASC
+        LIMIT $1
+        FOR UPDATE SKIP LOCKED`
+
+	rows, err := s.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var expired []ExpiredMessage
+	for rows.Next() {
+		var m ExpiredMessage
+		if err := rows.Scan(&m.ID, &m.ChatID); err != nil {
+			return nil, err
+		}
+		expired = append(expired, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return expired, nil
+}
+
+// DeleteByIDs - xabarlarni butunlay o'chiradi.
+// Reaksiyalar, revisionlar va boshqa bog'liq yozuvlar FK ON DELETE CASCADE orqali birga o'chadi,
+// attachment'lar esa blob key'lari uchun oldinroq AttachmentStorage orqali o'chirilishi kerak.
+func (s *MessageStorage) DeleteByIDs(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := `DELETE FROM messages WHERE id = ANY($1::bigint[])`
+
+	_, err := s.db.ExecContext(ctx, query, pq.Array(ids))
+	return err
+}
+
+// Hide - "faqat men uchun o'chirish", boshqa a'zolarga ta'sir qilmaydi
+func (s *MessageStorage) Hide(ctx context.Context, msgID, userID int64) error {
+	query := `INSERT INTO message_hidden (message_id, user_id)
+              VALUES ($1, $2)
+              ON CONFLICT (message_id, user_id) DO NOTHING`
+
+	_, err := s.db.ExecContext(ctx, query, msgID, userID)
+	return err
+}
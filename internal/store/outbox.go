@@ -0,0 +1,132 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// outboxLockKey - relay'lar uchun advisory lock: batch'ni faqat bitta instance band qiladi;
+// Claim esa oldingi batch yuborilmaguncha yangisini bermaydi, shunda event'lar id tartibida yuboriladi
+const outboxLockKey = "chatx_outbox_relay"
+
+// OutboxEntry - hali hub'ga yuborilmagan realtime event
+type OutboxEntry struct {
+	ID        int64
+	EventType string
+	Payload   []byte
+	CreatedAt string
+}
+
+type OutboxStorage struct {
+	db DBTX
+}
+
+// Add - event'ni outbox'ga yozadi. Asosiy o'zgarish bilan bitta transaction ichida chaqirilishi kerak.
+func (s *OutboxStorage) Add(ctx context.Context, eventType string, payload []byte) error {
+	query := `INSERT INTO outbox_events (event_type, payload) VALUES ($1, $2)`
+
+	_, err := s.db.ExecContext(ctx, query, eventType, payload)
+	return err
+}
+
+// TryLock - relay lock'ini transaction oxirigacha oladi. Lock boshqa instance'da bo'lsa false qaytadi.
+// Lock faqat batch'ni band qilish paytida ushlanadi, yuborish esa transaction'dan tashqarida bo'ladi.
+func (s *OutboxStorage) TryLock(ctx context.Context) (bool, error) {
+	var locked bool
+	err := s.db.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock(hashtext($1))`, outboxLockKey).Scan(&locked)
+	return locked, err
+}
+
+// Claim - yuborilmagan event'larni id tartibida lease bilan band qiladi. Boshqa relay band qilgan
+// (lease'i tugamagan) event bo'lsa hech narsa qaytarmaydi, shunda event'lar tartibi buzilmaydi.
+// TryLock bilan bitta transaction ichida chaqirilishi kerak.
+func (s *OutboxStorage) Claim(ctx context.Context, limit int, lease time.Duration) ([]OutboxEntry, error) {
+	var busy bool
+	err := s.db.QueryRowContext(ctx, `
+        SELECT EXISTS (
+            SELECT 1 FROM outbox_events
+            WHERE published_at IS NULL AND claimed_until > NOW()
+        )`).Scan(&busy)
+	if err != nil || busy {
+		return nil, err
+	}
+
+	query := `
+        UPDATE outbox_events
+        SET claimed_until = NOW() + make_interval(secs => $2)
+        WHERE id IN (
+            SELECT id FROM outbox_events
+            WHERE published_at IS NULL
+            ORDER BY id ASC
+            LIMIT $1
+        )
+        RETURNING id, event_type, payload, created_at`
+
+	rows, err := s.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []OutboxEntry{}
+	for rows.Next() {
+		var e OutboxEntry
+		if err := rows.Scan(&e.ID, &e.EventType, &e.Payload, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING tartibni kafolatlamaydi
+	slices.SortFunc(entries, func(a, b OutboxEntry) int { return cmp.Compare(a.ID, b.ID) })
+	return entries, nil
+}
+
+// MarkPublished - event'larni yuborilgan deb belgilaydi
+func (s *OutboxStorage) MarkPublished(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := `UPDATE outbox_events SET published_at = NOW(), claimed_until = NULL WHERE id = ANY($1::bigint[])`
+
+	_, err := s.db.ExecContext(ctx, query, pq.Array(ids))
+	return err
+}
+
+// Release - yuborilmagan event'lar band qilinishini bekor qiladi, keyingi relay ularni darhol qayta oladi
+func (s *OutboxStorage) Release(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := `UPDATE outbox_events SET claimed_until = NULL WHERE id = ANY($1::bigint[]) AND published_at IS NULL`
+
+	_, err := s.db.ExecContext(ctx, query, pq.Array(ids))
+	return err
+}
+
+// Prune - retention'dan oldin yuborilgan event'larni o'chiradi. Yuborilmaganlar saqlanadi.
+func (s *OutboxStorage) Prune(ctx context.Context, retention time.Duration, limit int) (int64, error) {
+	query := `
+        DELETE FROM outbox_events
+        WHERE id IN (
+            SELECT id FROM outbox_events
+            WHERE published_at < NOW() - make_interval(secs => $1)
+            LIMIT $2
+        )`
+
+	res, err := s.db.ExecContext(ctx, query, retention.Seconds(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -1,8 +1,11 @@
 package store
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type PaginationQuery struct {
@@ -43,3 +46,128 @@ func (pg PaginationQuery) Parse(r *http.Request) (*PaginationQuery, error) {
 
 	return &pg, nil
 }
+
+type CursorQuery struct {
+	Limit  int   `json:"limit" validate:"gte=1,lte=100"`
+	Before int64 `json:"before" validate:"gte=0"`
+	After  int64 `json:"after" validate:"gte=0"`
+	Around int64 `json:"around" validate:"gte=0"`
+}
+
+func (cq CursorQuery) Parse(r *http.Request) (*CursorQuery, error) {
+
+	limit := r.URL.Query().Get("limit")
+
+	if limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return nil, err
+		}
+
+		cq.Limit = l
+	}
+
+	cursors := 0
+	for _, p := range []struct {
+		name string
+		dst  *int64
+	}{
+		{"before", &cq.Before},
+		{"after", &cq.After},
+		{"around", &cq.Around},
+	} {
+		raw := r.URL.Query().Get(p.name)
+		if raw == "" {
+			continue
+		}
+
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if id <= 0 {
+			return nil, errors.New(p.name + " must be greater than 0")
+		}
+
+		*p.dst = id
+		cursors++
+	}
+
+	if cursors > 1 {
+		return nil, errors.New("only one of before, after or around can be set")
+	}
+
+	return &cq, nil
+}
+
+type SearchQuery struct {
+	Query    string     `json:"q" validate:"required,max=200"`
+	ChatID   int64      `json:"chat_id" validate:"gte=0"`
+	SenderID int64      `json:"sender_id" validate:"gte=0"`
+	From     *time.Time `json:"from"`
+	To       *time.Time `json:"to"`
+	Limit    int        `json:"limit" validate:"gte=1,lte=50"`
+	Cursor   string     `json:"cursor" validate:"max=100"`
+}
+
+func (sq SearchQuery) Parse(r *http.Request) (*SearchQuery, error) {
+	q := r.URL.Query()
+
+	sq.Query = strings.TrimSpace(q.Get("q"))
+	sq.Cursor = q.Get("cursor")
+
+	if limit := q.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return nil, err
+		}
+
+		sq.Limit = l
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  *int64
+	}{
+		{"chat_id", &sq.ChatID},
+		{"sender_id", &sq.SenderID},
+	} {
+		raw := q.Get(p.name)
+		if raw == "" {
+			continue
+		}
+
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		*p.dst = id
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"from", &sq.From},
+		{"to", &sq.To},
+	} {
+		raw := q.Get(p.name)
+		if raw == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, errors.New(p.name + " must be an RFC3339 timestamp")
+		}
+
+		*p.dst = &t
+	}
+
+	if sq.From != nil && sq.To != nil && sq.To.Before(*sq.From) {
+		return nil, errors.New("to must not be before from")
+	}
+
+	return &sq, nil
+}
@@ -0,0 +1,51 @@
+package store
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCursorQueryParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    CursorQuery
+		wantErr bool
+	}{
+		{name: "defaults", query: "", want: CursorQuery{Limit: 50}},
+		{name: "limit only", query: "limit=20", want: CursorQuery{Limit: 20}},
+		{name: "before", query: "before=10", want: CursorQuery{Limit: 50, Before: 10}},
+		{name: "after", query: "after=10&limit=5", want: CursorQuery{Limit: 5, After: 10}},
+		{name: "around", query: "around=10", want: CursorQuery{Limit: 50, Around: 10}},
+		{name: "empty cursor is ignored", query: "before=&after=7", want: CursorQuery{Limit: 50, After: 7}},
+		{name: "before and after", query: "before=10&after=5", wantErr: true},
+		{name: "before and around", query: "before=10&around=5", wantErr: true},
+		{name: "all three", query: "before=1&after=2&around=3", wantErr: true},
+		{name: "zero before", query: "before=0", wantErr: true},
+		{name: "zero after", query: "after=0", wantErr: true},
+		{name: "zero around", query: "around=0", wantErr: true},
+		{name: "negative cursor", query: "after=-1", wantErr: true},
+		{name: "cursor not a number", query: "before=abc", wantErr: true},
+		{name: "limit not a number", query: "limit=ten", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/messages?"+tt.query, nil)
+
+			got, err := CursorQuery{Limit: 50}.Parse(r)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", *got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *got != tt.want {
+				t.Fatalf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
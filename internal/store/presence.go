@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+// Presence - user'ning online holati va oxirgi ko'ringan vaqti.
+// Status WebSocket ulanishlaridan olinadi, LastSeenAt esa bazadan
+// (user last-seen'ni yashirgan bo'lsa nil).
+type Presence struct {
+	Status     string  `json:"status"`
+	LastSeenAt *string `json:"last_seen_at"`
+}
+
+type PresenceStorage struct {
+	db DBTX
+}
+
+// UpdateLastSeen - last_seen_at ni hozirgi vaqtga o'rnatadi va boshqalarga ko'rinadigan qiymatni qaytaradi
+func (s *PresenceStorage) UpdateLastSeen(ctx context.Context, userID int64) (*string, error) {
+	query := `
+        UPDATE users
+        SET last_seen_at = NOW()
+        WHERE id = $1
+        RETURNING CASE WHEN hide_last_seen THEN NULL ELSE last_seen_at END`
+
+	var lastSeen *string
+	err := s.db.QueryRowContext(ctx, query, userID).Scan(&lastSeen)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, SqlNotfound
+		default:
+			return nil, err
+		}
+	}
+
+	return lastSeen, nil
+}
+
+// GetLastSeen - berilgan userlarning ko'rinadigan last_seen_at qiymatlari
+func (s *PresenceStorage) GetLastSeen(ctx context.Context, userIDs []int64) (map[int64]*string, error) {
+	result := make(map[int64]*string)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+        SELECT id, CASE WHEN hide_last_seen THEN NULL ELSE last_seen_at END
+        FROM users
+        WHERE id = ANY($1)`
+
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(userIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var lastSeen *string
+		if err := rows.Scan(&id, &lastSeen); err != nil {
+			return nil, err
+		}
+		result[id] = lastSeen
+	}
+
+	return result, rows.Err()
+}
+
+func (s *PresenceStorage) SetHideLastSeen(ctx context.Context, userID int64, hide bool) error {
+	query := `UPDATE users SET hide_last_seen = $2 WHERE id = $1`
+
+	res, err := s.db.ExecContext(ctx, query, userID, hide)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return SqlNotfound
+	}
+
+	return nil
+}
+
+// GetContactIDs - user bilan kamida bitta umumiy chatga ega bo'lgan boshqa userlar
+func (s *PresenceStorage) GetContactIDs(ctx context.Context, userID int64) ([]int64, error) {
+	query := `
+        SELECT DISTINCT cm2.user_id
+        FROM chat_members cm1
+        JOIN chat_members cm2 ON cm2.chat_id = cm1.chat_id
+        WHERE cm1.user_id = $1 AND cm2.user_id != $1`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+)
+
+type Reaction struct {
+	MessageID int64
+	UserID    int64
+	Emoji     string
+	CreatedAt string
+}
+
+// ReactionSummary - bitta xabardagi bitta emoji bo'yicha yig'indi
+type ReactionSummary struct {
+	Emoji       string
+	Count       int
+	ReactedByMe bool
+}
+
+type ReactionStorage struct {
+	db DBTX
+}
+
+func (s *ReactionStorage) Add(ctx context.Context, reaction *Reaction) error {
+	query := `INSERT INTO message_reactions (message_id, user_id, emoji)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (message_id, user_id, emoji) DO NOTHING`
+
+	_, err := s.db.ExecContext(ctx, query, reaction.MessageID, reaction.UserID, reaction.Emoji)
+	return err
+}
+
+func (s *ReactionStorage) Remove(ctx context.Context, msgID, userID int64, emoji string) error {
+	query := `DELETE FROM message_reactions WHERE message_id = $1 AND user_id = $2 AND emoji = $3`
+
+	result, err := s.db.ExecContext(ctx, query, msgID, userID, emoji)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return SqlNotfound
+	}
+
+	return nil
+}
+
+func (s *ReactionStorage) DeleteByMessage(ctx context.Context, msgID int64) error {
+	query := `DELETE FROM message_reactions WHERE message_id = $1`
+
+	_, err := s.db.ExecContext(ctx, query, msgID)
+	return err
+}
+
+// GetSummaries - berilgan xabarlar uchun reaksiyalarni emoji bo'yicha guruhlaydi
+func (s *ReactionStorage) GetSummaries(ctx context.Context, msgIDs []int64, viewerID int64) (map[int64][]ReactionSummary, error) {
+	summaries := make(map[int64][]ReactionSummary)
+	if len(msgIDs) == 0 {
+		return summaries, nil
+	}
+
+	query := `
+        SELECT message_id,
+               emoji,
+               COUNT(*) AS reaction_count,
+               BOOL_OR(user_id = $2) AS reacted_by_me
+        FROM message_reactions
+        WHERE message_id = ANY($1)
+        GROUP BY message_id, emoji
+        ORDER BY message_id, MIN(created_at) ASC`
+
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(msgIDs), viewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var msgID int64
+		var r ReactionSummary
+		if err := rows.Scan(&msgID, &r.Emoji, &r.Count, &r.ReactedByMe); err != nil {
+			return nil, err
+		}
+		summaries[msgID] = append(summaries[msgID], r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
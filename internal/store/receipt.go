@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+)
+
+type Receipt struct {
+	UserID      int64
+	Username    string
+	DeliveredAt *string
+	ReadAt      *string
+}
+
+type ReceiptStorage struct {
+	db DBTX
+}
+
+// Delivery - xabar user'ning WebSocket client'iga yetkazilgani haqidagi yozuv
+type Delivery struct {
+	MessageID int64
+	UserID    int64
+}
+
+// MarkDelivered - xabarlar userlarning WebSocket client'lariga yetkazilganini bitta so'rovda yozadi.
+// Faqat yangi qo'shilgan yozuvlar qaytadi (avval yozilganlar va yuboruvchining o'zi tashlab ketiladi).
+func (s *ReceiptStorage) MarkDelivered(ctx context.Context, deliveries []Delivery) ([]Delivery, error) {
+	if len(deliveries) == 0 {
+		return nil, nil
+	}
+
+	msgIDs := make([]int64, len(deliveries))
+	userIDs := make([]int64, len(deliveries))
+	for i, d := range deliveries {
+		msgIDs[i], userIDs[i] = d.MessageID, d.UserID
+	}
+
+	query := `
+        INSERT INTO message_deliveries (message_id, user_id)
+        SELECT m.id, d.user_id
+        FROM unnest($1::bigint[], $2::bigint[]) AS d(message_id, user_id)
+        JOIN messages m ON m.id = d.message_id AND m.sender_id <> d.user_id
+        ON CONFLICT (message_id, user_id) DO NOTHING
+        RETURNING message_id, user_id`
+
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(msgIDs), pq.Array(userIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var created []Delivery
+	for rows.Next() {
+		var d Delivery
+		if err := rows.Scan(&d.MessageID, &d.UserID); err != nil {
+			return nil, err
+		}
+		created = append(created, d)
+	}
+
+	return created, rows.Err()
+}
+
+// GetByMessageID - yuboruvchidan boshqa barcha chat a'zolari uchun yetkazilgan/o'qilgan vaqtlar
+func (s *ReceiptStorage) GetByMessageID(ctx context.Context, msgID int64) ([]Receipt, error) {
+	query := `
+        SELECT u.id, u.username, md.delivered_at, r.read_at
+        FROM messages m
+        JOIN chat_members cm ON cm.chat_id = m.chat_id AND cm.user_id <> m.sender_id
+        JOIN users u ON u.id = cm.user_id
+        LEFT JOIN message_deliveries md ON md.message_id = m.id AND md.user_id = cm.user_id
+        LEFT JOIN LATERAL (
+            -- cursor shu xabardan birinchi marta o'tgan vaqt
+            SELECT MIN(h.read_at) AS read_at
+            FROM read_cursor_history h
+            WHERE h.chat_id = m.chat_id
+              AND h.user_id = cm.user_id
+              AND h.last_read_message_id >= m.id
+        ) r ON cm.last_read_message_id >= m.id
+        WHERE m.id = $1
+        ORDER BY r.read_at ASC NULLS LAST, md.delivered_at ASC NULLS LAST, u.username ASC`
+
+	rows, err := s.db.QueryContext(ctx, query, msgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	receipts := []Receipt{}
+	for rows.Next() {
+		var r Receipt
+		if err := rows.Scan(&r.UserID, &r.Username, &r.DeliveredAt, &r.ReadAt); err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, r)
+	}
+
+	return receipts, rows.Err()
+}
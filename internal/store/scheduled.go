@@ -0,0 +1,132 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	ScheduledPending   = "pending"
+	ScheduledSent      = "sent"
+	ScheduledCancelled = "cancelled"
+	ScheduledFailed    = "failed"
+)
+
+type ScheduledMessage struct {
+	ID          int64
+	ChatID      int64
+	SenderID    int64
+	MessageText string
+	ReplyToID   *int64
+	SendAt      time.Time
+	Status      string
+	MessageID   *int64
+	CreatedAt   string
+}
+
+type ScheduledMessageStorage struct {
+	db DBTX
+}
+
+func (s *ScheduledMessageStorage) Create(ctx context.Context, msg *ScheduledMessage) error {
+	query := `INSERT INTO scheduled_messages (chat_id, sender_id, message_text, reply_to_message_id, send_at)
+	VALUES ($1, $2, $3, $4, $5) RETURNING id, status, created_at`
+
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		msg.ChatID,
+		msg.SenderID,
+		msg.MessageText,
+		msg.ReplyToID,
+		msg.SendAt,
+	).Scan(
+		&msg.ID,
+		&msg.Status,
+		&msg.CreatedAt,
+	)
+}
+
+// GetPendingByUserID - userning hali yuborilmagan rejalashtirilgan xabarlari
+func (s *ScheduledMessageStorage) GetPendingByUserID(ctx context.Context, userID int64) ([]ScheduledMessage, error) {
+	query := `
+        SELECT id, chat_id, sender_id, message_text, reply_to_message_id, send_at, status, message_id, created_at
+        FROM scheduled_messages
+        WHERE sender_id = $1 AND status = 'pending'
+        ORDER BY send_at ASC`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanScheduledMessages(rows)
+}
+
+func (s *ScheduledMessageStorage) Cancel(ctx context.Context, id, userID int64) error {
+	query := `UPDATE scheduled_messages SET status = 'cancelled', processed_at = NOW()
+              WHERE id = $1 AND sender_id = $2 AND status = 'pending'`
+
+	result, err := s.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return SqlNotfound
+	}
+
+	return nil
+}
+
+// LockDue - vaqti kelgan xabarlarni qulflab oladi. Transaction ichida chaqirilishi kerak,
+// SKIP LOCKED tufayli bir nechta dispatcher bir xil xabarni ikki marta olmaydi.
+func (s *ScheduledMessageStorage) LockDue(ctx context.Context, limit int) ([]ScheduledMessage, error) {
+	query := `
+        SELECT id, chat_id, sender_id, message_text, reply_to_message_id, send_at, status, message_id, created_at
+        FROM scheduled_messages
+        WHERE status = 'pending' AND send_at <= NOW()
+        ORDER BY send_at ASC
+        LIMIT $1
+        FOR UPDATE SKIP LOCKED`
+
+	rows, err := s.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanScheduledMessages(rows)
+}
+
+func (s *ScheduledMessageStorage) MarkProcessed(ctx context.Context, id int64, status string, messageID *int64) error {
+	query := `UPDATE scheduled_messages SET status = $2, message_id = $3, processed_at = NOW() WHERE id = $1`
+
+	_, err := s.db.ExecContext(ctx, query, id, status, messageID)
+	return err
+}
+
+func scanScheduledMessages(rows *sql.Rows) ([]ScheduledMessage, error) {
+	var messages []ScheduledMessage
+	for rows.Next() {
+		var m ScheduledMessage
+		if err := rows.Scan(
+			&m.ID, &m.ChatID, &m.SenderID, &m.MessageText, &m.ReplyToID,
+			&m.SendAt, &m.Status, &m.MessageID, &m.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
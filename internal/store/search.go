@@ -0,0 +1,189 @@
+package store
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ts_headline matnni escape qilmaydi, shuning uchun topilgan so'zlar HTML bo'lmagan
+// (Unicode private use) belgilar bilan o'raladi: matn Go'da escape qilingach ular <mark> ga almashtiriladi.
+// Xabar matnidagi shu belgilar headline'dan oldin olib tashlanadi.
+const (
+	snippetMarkStart = "\uE000"
+	snippetMarkStop  = "\uE001"
+	pgSnippetMarks   = "U&'\\E000\\E001'"
+)
+
+var snippetMarks = strings.NewReplacer(snippetMarkStart, "<mark>", snippetMarkStop, "</mark>")
+
+// highlightSnippet - snippet'ni HTML-escape qiladi va belgilangan so'zlarni <mark> bilan o'raydi
+func highlightSnippet(snippet string) string {
+	return snippetMarks.Replace(html.EscapeString(snippet))
+}
+
+type SearchResult struct {
+	MessageID  int64
+	ChatID     int64
+	ChatName   string
+	SenderID   int64
+	SenderName string
+	Snippet    string
+	Rank       float32
+	CreatedAt  string
+}
+
+type SearchPage struct {
+	Results    []SearchResult
+	NextCursor *string
+}
+
+// Search - user a'zo bo'lgan chatlardagi xabarlarni full-text qidiradi.
+// Natijalar rank bo'yicha, teng rank'da yangi xabar birinchi tartiblanadi;
+// cursor oxirgi natijaning (rank, id) juftligidan iborat.
+func (s *MessageStorage) Search(ctx context.Context, viewerID int64, sq *SearchQuery) (*SearchPage, error) {
+	args := []any{viewerID, sq.Query, sq.Limit + 1}
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	var filters []string
+	if sq.ChatID > 0 {
+		filters = append(filters, "m.chat_id = "+arg(sq.ChatID))
+	}
+	if sq.SenderID > 0 {
+		filters = append(filters, "m.sender_id = "+arg(sq.SenderID))
+	}
+	if sq.From != nil {
+		filters = append(filters, "m.created_at >= "+arg(*sq.From))
+	}
+	if sq.To != nil {
+		filters = append(filters, "m.created_at < "+arg(*sq.To))
+	}
+
+	cursorFilter := ""
+	if sq.Cursor != "" {
+		rank, id, err := decodeSearchCursor(sq.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		r, i := arg(rank), arg(id)
+		cursorFilter = fmt.Sprintf("WHERE (r.rank < %s::real OR (r.rank = %s::real AND r.id < %s))", r, r, i)
+	}
+
+	extra := ""
+	if len(filters) > 0 {
+		extra = "AND " + strings.Join(filters, " AND ")
+	}
+
+	query := `
+    WITH q AS (SELECT websearch_to_tsquery('simple', $2) AS query)
+    SELECT r.id, r.chat_id, r.chat_name, r.sender_id, r.sender_name,
+           ts_headline('simple', translate(r.message_text, ` + pgSnippetMarks + `, ''), q.query,
+               'StartSel=` + snippetMarkStart + `, StopSel=` + snippetMarkStop + `, MaxFragments=2, MaxWords=20, MinWords=5'),
+           r.rank, r.created_at
+    FROM (
+        SELECT m.id, m.chat_id, m.sender_id, m.message_text, m.created_at,
+               u.username AS sender_name,
+               CASE
+                   WHEN c.chat_type = 'group' THEN COALESCE(gi.group_name, 'Group')
+                   ELSE (
+                       SELECT u2.username FROM chat_members cm2
+                       JOIN users u2 ON u2.id = cm2.user_id
+                       WHERE cm2.chat_id = c.id AND cm2.user_id != $1 LIMIT 1
+                   )
+               END AS chat_name,
+               ts_rank(m.search_vector, q.query) AS rank
+        FROM messages m
+        CROSS JOIN q
+        JOIN chat_members cm ON cm.chat_id = m.chat_id AND cm.user_id = $1
+        JOIN chats c ON c.id = m.chat_id
+        JOIN users u ON u.id = m.sender_id
+        LEFT JOIN group_info gi ON gi.chat_id = c.id
+        WHERE m.search_vector @@ q.query
+          AND m.deleted_at IS NULL
+          AND (m.expires_at IS NULL OR m.expires_at > NOW())
+          AND NOT EXISTS (
+              SELECT 1 FROM message_hidden mh
+              WHERE mh.message_id = m.id AND mh.user_id = $1
+          )
+          ` + extra + `
+    ) r
+    CROSS JOIN q
+    ` + cursorFilter + `
+    ORDER BY r.rank DESC, r.id DESC
+    LIMIT $3`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+	for rows.Next() {
+		var res SearchResult
+		if err := rows.Scan(
+			&res.MessageID,
+			&res.ChatID,
+			&res.ChatName,
+			&res.SenderID,
+			&res.SenderName,
+			&res.Snippet,
+			&res.Rank,
+			&res.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		res.Snippet = highlightSnippet(res.Snippet)
+		results = append(results, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := &SearchPage{Results: results}
+	if len(results) > sq.Limit {
+		page.Results = results[:sq.Limit]
+		last := page.Results[sq.Limit-1]
+		cursor := encodeSearchCursor(last.Rank, last.MessageID)
+		page.NextCursor = &cursor
+	}
+
+	return page, nil
+}
+
+func encodeSearchCursor(rank float32, id int64) string {
+	raw := strconv.FormatFloat(float64(rank), 'g', -1, 32) + ":" + strconv.FormatInt(id, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeSearchCursor(cursor string) (string, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, ErrInvalidCursor
+	}
+
+	rankPart, idPart, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return "", 0, ErrInvalidCursor
+	}
+
+	if _, err := strconv.ParseFloat(rankPart, 32); err != nil {
+		return "", 0, ErrInvalidCursor
+	}
+
+	id, err := strconv.ParseInt(idPart, 10, 64)
+	if err != nil || id <= 0 {
+		return "", 0, ErrInvalidCursor
+	}
+
+	return rankPart, id, nil
+}
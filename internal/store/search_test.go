@@ -0,0 +1,130 @@
+package store
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDecodeSearchCursor(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	tests := []struct {
+		name     string
+		cursor   string
+		wantRank string
+		wantID   int64
+		wantErr  bool
+	}{
+		{name: "round trip", cursor: encodeSearchCursor(0.0607927, 42), wantRank: "0.0607927", wantID: 42},
+		{name: "zero rank", cursor: encode("0:7"), wantRank: "0", wantID: 7},
+		{name: "exponent rank", cursor: encode("1e-05:9"), wantRank: "1e-05", wantID: 9},
+		{name: "not base64", cursor: "!!!", wantErr: true},
+		{name: "padded base64", cursor: base64.URLEncoding.EncodeToString([]byte("0.5:1")), wantErr: true},
+		{name: "empty", cursor: "", wantErr: true},
+		{name: "missing separator", cursor: encode("0.5"), wantErr: true},
+		{name: "rank not a number", cursor: encode("abc:1"), wantErr: true},
+		{name: "id not a number", cursor: encode("0.5:abc"), wantErr: true},
+		{name: "zero id", cursor: encode("0.5:0"), wantErr: true},
+		{name: "negative id", cursor: encode("0.5:-3"), wantErr: true},
+		{name: "sql in rank", cursor: encode("0.5);DROP TABLE messages;--:1"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rank, id, err := decodeSearchCursor(tt.cursor)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidCursor) {
+					t.Fatalf("err = %v, want ErrInvalidCursor", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rank != tt.wantRank || id != tt.wantID {
+				t.Fatalf("got (%q, %d), want (%q, %d)", rank, id, tt.wantRank, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestSearchQueryParseTimeRange(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		query    string
+		wantFrom *time.Time
+		wantTo   *time.Time
+		wantErr  bool
+	}{
+		{name: "no range", query: "q=hi"},
+		{name: "from only", query: "q=hi&from=2024-01-01T00:00:00Z", wantFrom: &from},
+		{name: "to only", query: "q=hi&to=2024-02-01T12:30:00Z", wantTo: &to},
+		{name: "from and to", query: "q=hi&from=2024-01-01T00:00:00Z&to=2024-02-01T12:30:00Z", wantFrom: &from, wantTo: &to},
+		{name: "equal bounds", query: "q=hi&from=2024-01-01T00:00:00Z&to=2024-01-01T00:00:00Z", wantFrom: &from, wantTo: &from},
+		{name: "offset timezone", query: "q=hi&from=2024-01-01T05:00:00%2B05:00", wantFrom: &from},
+		{name: "to before from", query: "q=hi&from=2024-02-01T12:30:00Z&to=2024-01-01T00:00:00Z", wantErr: true},
+		{name: "date without time", query: "q=hi&from=2024-01-01", wantErr: true},
+		{name: "not a timestamp", query: "q=hi&to=yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/search?"+tt.query, nil)
+
+			got, err := SearchQuery{Limit: 20}.Parse(r)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", *got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !sameTime(got.From, tt.wantFrom) {
+				t.Fatalf("from = %v, want %v", got.From, tt.wantFrom)
+			}
+			if !sameTime(got.To, tt.wantTo) {
+				t.Fatalf("to = %v, want %v", got.To, tt.wantTo)
+			}
+		})
+	}
+}
+
+func sameTime(got, want *time.Time) bool {
+	if got == nil || want == nil {
+		return got == want
+	}
+	return got.Equal(*want)
+}
+
+func TestHighlightSnippet(t *testing.T) {
+	tests := []struct {
+		name    string
+		snippet string
+		want    string
+	}{
+		{name: "plain", snippet: "hello world", want: "hello world"},
+		{name: "highlight", snippet: "say " + snippetMarkStart + "hello" + snippetMarkStop + " again", want: "say <mark>hello</mark> again"},
+		{name: "script is escaped", snippet: "<script>alert(1)</script> " + snippetMarkStart + "hi" + snippetMarkStop, want: "&lt;script&gt;alert(1)&lt;/script&gt; <mark>hi</mark>"},
+		{name: "user mark tag is escaped", snippet: "<mark>fake</mark>", want: "&lt;mark&gt;fake&lt;/mark&gt;"},
+		{name: "attribute injection", snippet: `"><img src=x onerror=alert(1)>`, want: "&#34;&gt;&lt;img src=x onerror=alert(1)&gt;"},
+		{name: "ampersand and quote", snippet: "Tom & Jerry's", want: "Tom &amp; Jerry&#39;s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlightSnippet(tt.snippet); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -45,6 +45,7 @@ type Storage struct {
 		GetChatByUserID(ctx context.Context, id int64, searchTerm string) ([]*ChatInfo, error)
 		CheckChatP(ctx context.Context, users *Chatcheck) (int64, error)
 		Delete(ctx context.Context, chatID int) error
+		SetMessageTTL(ctx context.Context, chatID int64, ttlSeconds *int) error
 	}
 
 	MemberStorage interface {
@@ -63,20 +64,107 @@ type Storage struct {
 	MessageStorage interface {
 		Create(ctx context.Context, msg *Message) (Message, error)
 		GetByID(ctx context.Context, id int64) (*Message, error)
-		GetMessages(ctx context.Context, chatID int64) ([]MessageDetail, error)
-		MarkAsRead(ctx context.Context, chatID, userID int64) error
-		Update(ctx context.Context, msgID, userID int64, newText string) error
-		Delete(ctx context.Context, msgID, userID int64) error
+		GetMessages(ctx context.Context, chatID, viewerID int64, cq *CursorQuery) (*MessagePage, error)
+		GetThread(ctx context.Context, rootID, viewerID int64, cq *CursorQuery) (*MessagePage, error)
+		MarkAsRead(ctx context.Context, chatID, userID int64) (int64, error)
+		Update(ctx context.Context, msgID, userID int64, newText string) (string, error)
+		CreateRevision(ctx context.Context, msgID, userID int64) error
+		GetRevisions(ctx context.Context, msgID int64) ([]MessageRevision, error)
+		Delete(ctx context.Context, msgID, deletedBy int64) error
+		DeleteRevisions(ctx context.Context, msgID int64) error
+		Hide(ctx context.Context, msgID, userID int64) error
+		LockExpired(ctx context.Context, limit int) ([]ExpiredMessage, error)
+		DeleteByIDs(ctx context.Context, ids []int64) error
+		Search(ctx context.Context, viewerID int64, sq *SearchQuery) (*SearchPage, error)
+	}
+
+	ReactionStorage interface {
+		Add(ctx context.Context, reaction *Reaction) error
+		Remove(ctx context.Context, msgID, userID int64, emoji string) error
+		DeleteByMessage(ctx context.Context, msgID int64) error
+		GetSummaries(ctx context.Context, msgIDs []int64, viewerID int64) (map[int64][]ReactionSummary, error)
+	}
+
+	ScheduledMessageStorage interface {
+		Create(ctx context.Context, msg *ScheduledMessage) error
+		GetPendingByUserID(ctx context.Context, userID int64) ([]ScheduledMessage, error)
+		Cancel(ctx context.Context, id, userID int64) error
+		LockDue(ctx context.Context, limit int) ([]ScheduledMessage, error)
+		MarkProcessed(ctx context.Context, id int64, status string, messageID *int64) error
+	}
+
+	AttachmentStorage interface {
+		Create(ctx context.Context, a *Attachment) error
+		GetByID(ctx context.Context, id int64) (*Attachment, error)
+		AttachToMessage(ctx context.Context, ids []int64, messageID, uploaderID, chatID int64) ([]Attachment, error)
+		GetByMessageIDs(ctx context.Context, msgIDs []int64) (map[int64][]Attachment, error)
+		DeleteByMessage(ctx context.Context, msgID int64) ([]string, error)
+		DeleteByMessages(ctx context.Context, msgIDs []int64) ([]string, error)
+		DeleteByChat(ctx context.Context, chatID int64) ([]string, error)
+		DeleteOrphans(ctx context.Context, olderThan time.Duration, limit int) ([]string, error)
+		CreateThumbnail(ctx context.Context, t *AttachmentThumbnail) error
+		GetThumbnail(ctx context.Context, attachmentID int64, size string) (*AttachmentThumbnail, error)
+	}
+
+	MentionStorage interface {
+		ResolveMembers(ctx context.Context, chatID int64, usernames []string) ([]Mention, error)
+		Create(ctx context.Context, msgID int64, userIDs []int64) error
+		DeleteByMessage(ctx context.Context, msgID int64) error
+		GetByMessageIDs(ctx context.Context, msgIDs []int64) (map[int64][]Mention, error)
+		NextUnread(ctx context.Context, chatID, userID, afterID int64) (int64, error)
+	}
+
+	ReceiptStorage interface {
+		MarkDelivered(ctx context.Context, deliveries []Delivery) ([]Delivery, error)
+		GetByMessageID(ctx context.Context, msgID int64) ([]Receipt, error)
+	}
+
+	PresenceStorage interface {
+		UpdateLastSeen(ctx context.Context, userID int64) (*string, error)
+		GetLastSeen(ctx context.Context, userIDs []int64) (map[int64]*string, error)
+		SetHideLastSeen(ctx context.Context, userID int64, hide bool) error
+		GetContactIDs(ctx context.Context, userID int64) ([]int64, error)
+	}
+
+	EventStorage interface {
+		Append(ctx context.Context, userIDs []int64, eventType string, payload []byte) (map[int64]int64, error)
+		Since(ctx context.Context, userID, since int64, limit int) ([]Event, error)
+		Bounds(ctx context.Context, userID int64) (int64, int64, error)
+		Prune(ctx context.Context, retention time.Duration, limit int) (int64, error)
+	}
+
+	OutboxStorage interface {
+		Add(ctx context.Context, eventType string, payload []byte) error
+		TryLock(ctx context.Context) (bool, error)
+		Claim(ctx context.Context, limit int, lease time.Duration) ([]OutboxEntry, error)
+		MarkPublished(ctx context.Context, ids []int64) error
+		Release(ctx context.Context, ids []int64) error
+		Prune(ctx context.Context, retention time.Duration, limit int) (int64, error)
+	}
+
+	TicketStorage interface {
+		Create(ctx context.Context, hash string, ticket *Ticket) error
+		Consume(ctx context.Context, hash string) (*Ticket, error)
+		DeleteExpired(ctx context.Context) (int64, error)
 	}
 }
 
 func NewStorage(db *sql.DB) *Storage {
 	return &Storage{
-		UnitOfWork:     &SQLUnitOfWork{db},
-		UserStore:      &UserStore{db},
-		Chatstorage:    &Chatstorage{db},
-		MemberStorage:  &MemberStorage{db},
-		Groupstorage:   &Groupstorage{db},
-		MessageStorage: &MessageStorage{db},
+		UnitOfWork:              &SQLUnitOfWork{db},
+		UserStore:               &UserStore{db},
+		Chatstorage:             &Chatstorage{db},
+		MemberStorage:           &MemberStorage{db},
+		Groupstorage:            &Groupstorage{db},
+		MessageStorage:          &MessageStorage{db},
+		ReactionStorage:         &ReactionStorage{db},
+		ScheduledMessageStorage: &ScheduledMessageStorage{db},
+		AttachmentStorage:       &AttachmentStorage{db},
+		MentionStorage:          &MentionStorage{db},
+		ReceiptStorage:          &ReceiptStorage{db},
+		PresenceStorage:         &PresenceStorage{db},
+		EventStorage:            &EventStorage{db},
+		OutboxStorage:           &OutboxStorage{db},
+		TicketStorage:           &TicketStorage{db},
 	}
 }
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Ticket - WebSocket/SSE ulanishi uchun bir martalik ticket. IP bo'sh bo'lsa ticket IP'ga bog'lanmagan.
+type Ticket struct {
+	UserID    int64
+	IP        *string
+	ExpiresAt time.Time
+}
+
+type TicketStorage struct {
+	db DBTX
+}
+
+func (s *TicketStorage) Create(ctx context.Context, hash string, ticket *Ticket) error {
+	query := `INSERT INTO ws_tickets (ticket_hash, user_id, ip, expires_at) VALUES ($1, $2, $3, $4)`
+
+	_, err := s.db.ExecContext(ctx, query, hash, ticket.UserID, ticket.IP, ticket.ExpiresAt)
+	return err
+}
+
+// Consume - ticket'ni o'chirib qaytaradi, shuning uchun u faqat bir marta ishlatiladi.
+// Ticket topilmasa yoki muddati o'tgan bo'lsa SqlNotfound qaytadi.
+func (s *TicketStorage) Consume(ctx context.Context, hash string) (*Ticket, error) {
+	query := `
+        DELETE FROM ws_tickets
+        WHERE ticket_hash = $1
+        RETURNING user_id, ip, expires_at, expires_at > NOW()`
+
+	var t Ticket
+	var valid bool
+	err := s.db.QueryRowContext(ctx, query, hash).Scan(&t.UserID, &t.IP, &t.ExpiresAt, &valid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, SqlNotfound
+		}
+		return nil, err
+	}
+	if !valid {
+		return nil, SqlNotfound
+	}
+
+	return &t, nil
+}
+
+// DeleteExpired - ishlatilmay qolgan eski ticket'larni tozalaydi
+func (s *TicketStorage) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM ws_tickets WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -27,11 +27,20 @@ func (u *SQLUnitOfWork) Do(
 	}()
 
 	repos := &Storage{
-		UserStore:      &UserStore{tx},
-		Chatstorage:    &Chatstorage{tx},
-		MemberStorage:  &MemberStorage{tx},
-		Groupstorage:   &Groupstorage{tx},
-		MessageStorage: &MessageStorage{tx},
+		UserStore:               &UserStore{tx},
+		Chatstorage:             &Chatstorage{tx},
+		MemberStorage:           &MemberStorage{tx},
+		Groupstorage:            &Groupstorage{tx},
+		MessageStorage:          &MessageStorage{tx},
+		ReactionStorage:         &ReactionStorage{tx},
+		ScheduledMessageStorage: &ScheduledMessageStorage{tx},
+		AttachmentStorage:       &AttachmentStorage{tx},
+		MentionStorage:          &MentionStorage{tx},
+		ReceiptStorage:          &ReceiptStorage{tx},
+		PresenceStorage:         &PresenceStorage{tx},
+		EventStorage:            &EventStorage{tx},
+		OutboxStorage:           &OutboxStorage{tx},
+		TicketStorage:           &TicketStorage{tx},
 	}
 
 	if err := fn(ctx, repos); err != nil {
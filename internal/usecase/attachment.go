@@ -0,0 +1,243 @@
+package service
+
+import (
+	"bytes"
+	"chatX/internal/blob"
+	"chatX/internal/media"
+	"chatX/internal/store"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidAttachment = errors.New("attachment_ids must reference your own unsent uploads in this chat")
+	ErrUnsupportedImage  = errors.New("image format is not supported or the file is corrupted")
+)
+
+type Attachment struct {
+	ID          int64       `json:"id"`
+	FileName    string      `json:"file_name"`
+	MimeType    string      `json:"mime_type"`
+	SizeBytes   int64       `json:"size_bytes"`
+	URL         string      `json:"url"`
+	Width       *int        `json:"width"`
+	Height      *int        `json:"height"`
+	Placeholder *string     `json:"placeholder"`
+	Thumbnails  []Thumbnail `json:"thumbnails"`
+	CreatedAt   string      `json:"created_at"`
+}
+
+type Thumbnail struct {
+	Size   string `json:"size"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// StoredAttachment - download uchun kerak bo'ladigan ichki ma'lumotlar bilan birga
+type StoredAttachment struct {
+	Attachment
+	ChatID     int64
+	UploaderID int64
+	MessageID  *int64
+}
+
+func toAttachment(a store.Attachment) Attachment {
+	url := "/api/v1/attachments/" + strconv.FormatInt(a.ID, 10)
+
+	thumbnails := make([]Thumbnail, len(a.Thumbnails))
+	for i, t := range a.Thumbnails {
+		thumbnails[i] = Thumbnail{
+			Size:   t.Size,
+			URL:    url + "/thumbnails/" + t.Size,
+			Width:  t.Width,
+			Height: t.Height,
+		}
+	}
+
+	return Attachment{
+		ID:          a.ID,
+		FileName:    a.FileName,
+		MimeType:    a.MimeType,
+		SizeBytes:   a.SizeBytes,
+		URL:         url,
+		Width:       a.Width,
+		Height:      a.Height,
+		Placeholder: a.Placeholder,
+		Thumbnails:  thumbnails,
+		CreatedAt:   a.CreatedAt,
+	}
+}
+
+// UploadAttachment - faylni blob storage'ga yozadi va metadata'ni saqlaydi.
+// Rasmlar uchun GPS va boshqa metadata olib tashlanadi, o'lcham, placeholder va thumbnail'lar yaratiladi.
+// Fayl hali xabarga bog'lanmagan bo'ladi, xabar yaratilganda attachment_ids orqali bog'lanadi.
+func (s *MessageSRV) UploadAttachment(ctx context.Context, chatID, uploaderID int64, fileName, mimeType string, r io.Reader) (*Attachment, error) {
+	key := fmt.Sprintf("chats/%d/%s", chatID, uuid.New().String())
+
+	a := store.Attachment{
+		ChatID:     chatID,
+		UploaderID: uploaderID,
+		StorageKey: key,
+		FileName:   fileName,
+		MimeType:   mimeType,
+	}
+
+	var info *media.ImageInfo
+	if strings.HasPrefix(mimeType, "image/") {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+
+		// Metadata'sini tozalab bo'lmaydigan rasm saqlanmaydi: unda GPS qolib ketishi mumkin
+		data, err = media.StripMetadata(data, mimeType)
+		if err != nil {
+			return nil, ErrUnsupportedImage
+		}
+
+		info, err = media.ProcessImage(data, mimeType)
+		if err != nil && !errors.Is(err, media.ErrUnsupportedImage) {
+			return nil, err
+		}
+		if info != nil {
+			a.Width, a.Height, a.Placeholder = &info.Width, &info.Height, &info.Placeholder
+		}
+
+		r = bytes.NewReader(data)
+	}
+
+	size, err := s.blob.Put(ctx, key, r)
+	if err != nil {
+		return nil, err
+	}
+	a.SizeBytes = size
+	written := []string{key}
+
+	if info != nil {
+		for _, t := range info.Thumbnails {
+			thumbKey := key + "_" + t.Size
+			if _, err := s.blob.Put(ctx, thumbKey, bytes.NewReader(t.Data)); err != nil {
+				s.deleteBlobs(ctx, written)
+				return nil, err
+			}
+			written = append(written, thumbKey)
+
+			a.Thumbnails = append(a.Thumbnails, store.AttachmentThumbnail{
+				Size:       t.Size,
+				StorageKey: thumbKey,
+				Width:      t.Width,
+				Height:     t.Height,
+			})
+		}
+	}
+
+	err = s.repo.UnitOfWork.Do(ctx, func(ctx context.Context, repos *store.Storage) error {
+		if err := repos.AttachmentStorage.Create(ctx, &a); err != nil {
+			return err
+		}
+
+		for i := range a.Thumbnails {
+			a.Thumbnails[i].AttachmentID = a.ID
+			if err := repos.AttachmentStorage.CreateThumbnail(ctx, &a.Thumbnails[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.deleteBlobs(ctx, written)
+		return nil, err
+	}
+
+	result := toAttachment(a)
+	return &result, nil
+}
+
+func (s *MessageSRV) GetAttachment(ctx context.Context, id int64) (*StoredAttachment, error) {
+	a, err := s.repo.AttachmentStorage.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StoredAttachment{
+		Attachment: toAttachment(*a),
+		ChatID:     a.ChatID,
+		UploaderID: a.UploaderID,
+		MessageID:  a.MessageID,
+	}, nil
+}
+
+// OpenAttachment - fayl kontentini o'qish uchun ochadi, chaqiruvchi yopishi shart
+func (s *MessageSRV) OpenAttachment(ctx context.Context, id int64) (io.ReadCloser, error) {
+	a, err := s.repo.AttachmentStorage.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.blob.Open(ctx, a.StorageKey)
+}
+
+// OpenThumbnail - berilgan o'lchamdagi thumbnail'ni o'qish uchun ochadi, chaqiruvchi yopishi shart
+func (s *MessageSRV) OpenThumbnail(ctx context.Context, id int64, size string) (io.ReadCloser, error) {
+	t, err := s.repo.AttachmentStorage.GetThumbnail(ctx, id, size)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.blob.Open(ctx, t.StorageKey)
+}
+
+// DeleteOrphanUploads - olderThan'dan oldin yuklanib, hech qaysi xabarga bog'lanmagan fayllarni
+// o'chiradi. O'chirilgan blob'lar sonini (thumbnail'lar bilan) qaytaradi.
+func (s *MessageSRV) DeleteOrphanUploads(ctx context.Context, olderThan time.Duration, limit int) (int, error) {
+	var blobKeys []string
+
+	err := s.repo.UnitOfWork.Do(ctx, func(ctx context.Context, repos *store.Storage) error {
+		keys, err := repos.AttachmentStorage.DeleteOrphans(ctx, olderThan, limit)
+		blobKeys = keys
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.deleteBlobs(ctx, blobKeys)
+
+	return len(blobKeys), nil
+}
+
+func (s *MessageSRV) deleteBlobs(ctx context.Context, keys []string) {
+	deleteBlobs(ctx, s.blob, keys)
+}
+
+// deleteBlobs - transaction commit bo'lgach fayllarni o'chiradi. Xato bo'lsa fayl storage'da
+// qoladi, lekin u endi hech qaysi yozuvga bog'lanmagan, shuning uchun xato qaytarilmaydi.
+func deleteBlobs(ctx context.Context, blobs blob.Store, keys []string) {
+	for _, key := range keys {
+		_ = blobs.Delete(ctx, key)
+	}
+}
+
+func (s *MessageSRV) loadAttachments(ctx context.Context, msgIDs []int64) (map[int64][]Attachment, error) {
+	stored, err := s.repo.AttachmentStorage.GetByMessageIDs(ctx, msgIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64][]Attachment, len(stored))
+	for msgID, list := range stored {
+		for _, a := range list {
+			result[msgID] = append(result[msgID], toAttachment(a))
+		}
+	}
+
+	return result, nil
+}
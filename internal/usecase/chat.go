@@ -1,6 +1,7 @@
 package service
 
 import (
+	"chatX/internal/blob"
 	"chatX/internal/store"
 	"context"
 	"database/sql"
@@ -9,6 +10,7 @@ import (
 
 type ChatSRVC struct {
 	repo *store.Storage
+	blob blob.Store
 }
 
 type ChatReq struct {
@@ -130,14 +132,19 @@ func (s *ChatSRVC) CreateGroupChat(ctx context.Context, group *Group) (int64, er
 }
 
 type ChatInfo struct {
-	ChatID        int64  `json:"chat_id"`
-	ChatType      string `json:"chat_type"`
-	ChatName      string `json:"chat_name"`
-	UserRole      string `json:"user_role"`
-	JoinedAt      string `json:"joined_at"`
-	LastMessage   string `json:"last_message"`
-	LastMessageAt string `json:"last_message_at"`
-	UnreadCount   int    `json:"unread_count"` // Yangi qo'shildi
+	ChatID         int64  `json:"chat_id"`
+	ChatType       string `json:"chat_type"`
+	ChatName       string `json:"chat_name"`
+	UserRole       string `json:"user_role"`
+	JoinedAt       string `json:"joined_at"`
+	LastMessage    string `json:"last_message"`
+	LastMessageAt  string `json:"last_message_at"`
+	UnreadCount    int    `json:"unread_count"` // Yangi qo'shildi
+	UnreadMentions int    `json:"unread_mentions"`
+	MessageTTL     *int   `json:"message_ttl_seconds"`
+	// PeerID va Presence faqat private chatlar uchun (suhbatdosh)
+	PeerID   *int64          `json:"peer_id,omitempty"`
+	Presence *store.Presence `json:"presence,omitempty"`
 }
 
 func (s *ChatSRVC) GetUserChats(ctx context.Context, userID int64, searchTerm string) ([]*ChatInfo, error) {
@@ -177,11 +184,74 @@ func (s *ChatSRVC) Updatechat(ctx context.Context, group *Chatgroup) (*store.Gro
 	return chat, nil
 }
 
-func (s *ChatSRVC) DeleteChat(ctx context.Context, chatID int) error {
+// DeleteChat - chatni o'chiradi. chat_deleted event'i a'zolar ro'yxati bilan birga o'sha transaction'da yoziladi.
+// Chatdagi fayllar transaction commit bo'lgandan keyin storage'dan o'chiriladi.
+func (s *ChatSRVC) DeleteChat(ctx context.Context, actor *store.User, chatID int) error {
+	var blobKeys []string
 
-	if err := s.repo.Chatstorage.Delete(ctx, chatID); err != nil {
+	err := s.repo.UnitOfWork.Do(ctx, func(ctx context.Context, repos *store.Storage) error {
+		members, err := repos.MemberStorage.GetByChatID(ctx, chatID)
+		if err != nil {
+			return err
+		}
+
+		keys, err := repos.AttachmentStorage.DeleteByChat(ctx, int64(chatID))
+		if err != nil {
+			return err
+		}
+		blobKeys = keys
+
+		if err := repos.Chatstorage.Delete(ctx, chatID); err != nil {
+			return err
+		}
+
+		memberIDs := make([]int64, len(members))
+		for i, m := range members {
+			memberIDs[i] = m.ID
+		}
+
+		return enqueue(ctx, repos, OutboxChatDeleted, ChatDeletedEvent{
+			ChatID:        int64(chatID),
+			DeletedByID:   actor.ID,
+			DeletedByName: actor.UserName,
+			MemberIDs:     memberIDs,
+		})
+	})
+	if err != nil {
 		return err
 	}
 
+	deleteBlobs(ctx, s.blob, blobKeys)
+
 	return nil
 }
+
+// SetMessageTTL - disappearing messages sozlamasi. Groupda faqat owner/admin,
+// private chatda esa ikkala ishtirokchi ham o'zgartira oladi. ttlSeconds = 0 o'chiradi.
+func (s *ChatSRVC) SetMessageTTL(ctx context.Context, actorUserID, chatID int64, ttlSeconds int) error {
+	chat, err := s.repo.Chatstorage.GetByID(ctx, chatID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return store.SqlNotfound
+		}
+		return err
+	}
+
+	role, err := s.repo.MemberStorage.GetRole(ctx, chatID, actorUserID)
+	if err != nil {
+		if errors.Is(err, store.SqlNotfound) {
+			return store.SqlForbidden
+		}
+		return err
+	}
+	if chat.ChatType == "group" && role != RoleOwner && role != RoleAdmin {
+		return store.SqlForbidden
+	}
+
+	var ttl *int
+	if ttlSeconds > 0 {
+		ttl = &ttlSeconds
+	}
+
+	return s.repo.Chatstorage.SetMessageTTL(ctx, chatID, ttl)
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"chatX/internal/store"
+	"context"
+	"time"
+)
+
+type EventSRV struct {
+	repo *store.Storage
+}
+
+// EventPage - sync javobi. Truncated bo'lsa so'ralgan event'lar retention tufayli
+// o'chirilgan (yoki since noto'g'ri) va client holatini to'liq qayta yuklashi kerak.
+type EventPage struct {
+	Events    []store.Event
+	LastSeq   int64
+	HasMore   bool
+	Truncated bool
+}
+
+func (s *EventSRV) Append(ctx context.Context, userIDs []int64, eventType string, payload []byte) (map[int64]int64, error) {
+	return s.repo.EventStorage.Append(ctx, userIDs, eventType, payload)
+}
+
+// Sync - since'dan keyingi event'larni qaytaradi
+func (s *EventSRV) Sync(ctx context.Context, userID, since int64, limit int) (*EventPage, error) {
+	minSeq, lastSeq, err := s.repo.EventStorage.Bounds(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &EventPage{Events: []store.Event{}, LastSeq: lastSeq}
+	if since > lastSeq || (since < lastSeq && (minSeq == 0 || since+1 < minSeq)) {
+		page.Truncated = true
+	}
+
+	events, err := s.repo.EventStorage.Since(ctx, userID, since, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(events) > limit {
+		events = events[:limit]
+		page.HasMore = true
+	}
+	page.Events = events
+
+	return page, nil
+}
+
+func (s *EventSRV) Prune(ctx context.Context, retention time.Duration, limit int) (int64, error) {
+	return s.repo.EventStorage.Prune(ctx, retention, limit)
+}
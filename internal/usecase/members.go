@@ -60,10 +60,31 @@ func (s *MemberSRV) Add(ctx context.Context, actorUserID int64, chatID int, user
 		return ErrMemberAlreadyExists
 	}
 
-	return s.repo.MemberStorage.AddMember(ctx, &store.Member{
-		ChatID: int64(chatID),
-		UserID: int64(userID),
-		Rol:    RoleMember,
+	return s.repo.UnitOfWork.Do(ctx, func(ctx context.Context, repos *store.Storage) error {
+		if err := repos.MemberStorage.AddMember(ctx, &store.Member{
+			ChatID: int64(chatID),
+			UserID: int64(userID),
+			Rol:    RoleMember,
+		}); err != nil {
+			return err
+		}
+
+		user, err := repos.UserStore.GetUserByID(ctx, int64(userID))
+		if err != nil {
+			return err
+		}
+		actor, err := repos.UserStore.GetUserByID(ctx, actorUserID)
+		if err != nil {
+			return err
+		}
+
+		return enqueue(ctx, repos, OutboxMemberAdded, MemberEvent{
+			ChatID:    int64(chatID),
+			UserID:    int64(userID),
+			ActorID:   actorUserID,
+			Username:  user.UserName,
+			ActorName: actor.UserName,
+		})
 	})
 }
 
@@ -78,10 +99,15 @@ func (s *MemberSRV) Delete(ctx context.Context, actorUserID int64, chatID int, u
 		}
 	}
 
-	err := s.repo.MemberStorage.Delete(ctx, chatID, userID)
-	if err != nil {
-		return err
-	}
+	return s.repo.UnitOfWork.Do(ctx, func(ctx context.Context, repos *store.Storage) error {
+		if err := repos.MemberStorage.Delete(ctx, chatID, userID); err != nil {
+			return err
+		}
 
-	return nil
+		return enqueue(ctx, repos, OutboxMemberRemoved, MemberEvent{
+			ChatID:  int64(chatID),
+			UserID:  int64(userID),
+			ActorID: actorUserID,
+		})
+	})
 }
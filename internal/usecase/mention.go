@@ -0,0 +1,74 @@
+package service
+
+import (
+	"chatX/internal/store"
+	"context"
+	"regexp"
+	"strings"
+)
+
+// maxMentionsPerMessage - bitta xabardagi mention'lar soni cheklovi (spam'ga qarshi)
+const maxMentionsPerMessage = 50
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_])@([\p{L}\p{N}_.\-]+)`)
+
+type Mention struct {
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// parseMentions - matndan @username'larni ajratib oladi (kichik harfda, takrorlarsiz)
+func parseMentions(text string) []string {
+	seen := make(map[string]bool)
+	var usernames []string
+
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		name := strings.ToLower(strings.TrimRight(match[1], ".-"))
+		if name == "" || seen[name] {
+			continue
+		}
+
+		seen[name] = true
+		usernames = append(usernames, name)
+		if len(usernames) == maxMentionsPerMessage {
+			break
+		}
+	}
+
+	return usernames
+}
+
+// saveMentions - xabar matnidagi mention'larni chat a'zolariga moslab saqlaydi.
+// Yuboruvchi o'zini mention qilsa hisobga olinmaydi.
+func saveMentions(ctx context.Context, repos *store.Storage, msg store.Message) ([]Mention, error) {
+	usernames := parseMentions(msg.MessageText)
+	if len(usernames) == 0 {
+		return []Mention{}, nil
+	}
+
+	resolved, err := repos.MentionStorage.ResolveMembers(ctx, msg.ChatID, usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions := []Mention{}
+	userIDs := []int64{}
+	for _, m := range resolved {
+		if m.UserID == msg.SenderID {
+			continue
+		}
+		mentions = append(mentions, Mention{UserID: m.UserID, Username: m.Username})
+		userIDs = append(userIDs, m.UserID)
+	}
+
+	if err := repos.MentionStorage.Create(ctx, msg.ID, userIDs); err != nil {
+		return nil, err
+	}
+
+	return mentions, nil
+}
+
+// NextUnreadMention - "keyingi mention'ga o'tish" uchun: afterID dan keyingi o'qilmagan mention xabari
+func (s *MessageSRV) NextUnreadMention(ctx context.Context, chatID, userID, afterID int64) (int64, error) {
+	return s.repo.MentionStorage.NextUnread(ctx, chatID, userID, afterID)
+}
@@ -1,60 +1,162 @@
 package service
 
 import (
+	"chatX/internal/blob"
 	"chatX/internal/store"
 	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidReply        = errors.New("reply_to_message_id must reference a message in the same chat")
+	ErrDeleteWindowExpired = errors.New("message can no longer be deleted for everyone")
 )
 
 type Message struct {
-	ID          int64  `json:"id"`
-	ChatID      int64  `json:"chat_id"`
-	SenderID    int64  `json:"sender_id"`
-	MessageText string `json:"message_text"`
-	IsRead      bool   `json:"is_read"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
-	SenderName  string `json:"sender_name"`
-	ChatName    string `json:"chat_name"`
+	ID               int64        `json:"id"`
+	ChatID           int64        `json:"chat_id"`
+	SenderID         int64        `json:"sender_id"`
+	MessageText      string       `json:"message_text"`
+	IsRead           bool         `json:"is_read"`
+	CreatedAt        string       `json:"created_at"`
+	UpdatedAt        string       `json:"updated_at"`
+	SenderName       string       `json:"sender_name"`
+	ChatName         string       `json:"chat_name"`
+	ReplyToMessageID *int64       `json:"reply_to_message_id"`
+	EditedAt         *string      `json:"edited_at"`
+	IsEdited         bool         `json:"is_edited"`
+	DeletedAt        *string      `json:"deleted_at"`
+	IsDeleted        bool         `json:"is_deleted"`
+	AttachmentIDs    []int64      `json:"-"`
+	Attachments      []Attachment `json:"attachments"`
+	Mentions         []Mention    `json:"mentions"`
 }
 
 type MessageSRV struct {
 	repo *store.Storage
+	blob blob.Store
 }
 
 func (s *MessageSRV) Create(ctx context.Context, msg Message) (*Message, error) {
+	if err := s.validateReply(ctx, msg.ChatID, msg.ReplyToMessageID); err != nil {
+		return nil, err
+	}
+
 	req := store.Message{
 		ChatID:      msg.ChatID,
 		SenderID:    msg.SenderID,
 		MessageText: msg.MessageText,
+		ReplyToID:   msg.ReplyToMessageID,
 	}
 
-	message, err := s.repo.MessageStorage.Create(ctx, &req)
+	var result *Message
+	err := s.repo.UnitOfWork.Do(ctx, func(ctx context.Context, repos *store.Storage) error {
+		message, err := repos.MessageStorage.Create(ctx, &req)
+		if err != nil {
+			return err
+		}
+		result = toMessage(message)
+
+		result.Mentions, err = saveMentions(ctx, repos, message)
+		if err != nil {
+			return err
+		}
+
+		if len(msg.AttachmentIDs) > 0 {
+			attached, err := repos.AttachmentStorage.AttachToMessage(ctx, msg.AttachmentIDs, message.ID, msg.SenderID, msg.ChatID)
+			if err != nil {
+				return err
+			}
+			if len(attached) != len(msg.AttachmentIDs) {
+				return ErrInvalidAttachment
+			}
+
+			for _, a := range attached {
+				result.Attachments = append(result.Attachments, toAttachment(a))
+			}
+		}
+
+		return enqueue(ctx, repos, OutboxMessageCreated, result)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	m := Message{
-		ID:          message.ID,
-		ChatID:      message.ChatID,
-		SenderID:    message.SenderID,
-		MessageText: message.MessageText,
-		IsRead:      message.IsRead,
-		CreatedAt:   message.CreatedAt,
-		UpdatedAt:   message.UpdatedAt,
-		SenderName:  message.SenderName,
-		ChatName:    message.ChatName,
+	return result, nil
+}
+
+func (s *MessageSRV) validateReply(ctx context.Context, chatID int64, replyToID *int64) error {
+	if replyToID == nil {
+		return nil
 	}
 
-	return &m, nil
+	parent, err := s.repo.MessageStorage.GetByID(ctx, *replyToID)
+	if err != nil {
+		if errors.Is(err, store.SqlNotfound) {
+			return ErrInvalidReply
+		}
+		return err
+	}
+	if parent.ChatID != chatID {
+		return ErrInvalidReply
+	}
+
+	return nil
+}
+
+func toMessage(message store.Message) *Message {
+	return &Message{
+		ID:               message.ID,
+		ChatID:           message.ChatID,
+		SenderID:         message.SenderID,
+		MessageText:      message.MessageText,
+		IsRead:           message.IsRead,
+		CreatedAt:        message.CreatedAt,
+		UpdatedAt:        message.UpdatedAt,
+		SenderName:       message.SenderName,
+		ChatName:         message.ChatName,
+		ReplyToMessageID: message.ReplyToID,
+		Attachments:      []Attachment{},
+		Mentions:         []Mention{},
+	}
 }
 
 type MessageDetail struct {
+	ID          int64         `json:"id"`
+	Content     string        `json:"content"`
+	SenderID    int64         `json:"sender_id"`
+	SenderName  string        `json:"sender_name"`
+	CreatedAt   string        `json:"created_at"`
+	IsRead      bool          `json:"is_read"`
+	ReplyTo     *ReplyPreview `json:"reply_to"`
+	ReplyCount  int           `json:"reply_count"`
+	Reactions   []Reaction    `json:"reactions"`
+	Attachments []Attachment  `json:"attachments"`
+	Mentions    []Mention     `json:"mentions"`
+	EditedAt    *string       `json:"edited_at"`
+	IsEdited    bool          `json:"is_edited"`
+	DeletedAt   *string       `json:"deleted_at"`
+	IsDeleted   bool          `json:"is_deleted"`
+}
+
+type MessageRevision struct {
+	ID          int64  `json:"id"`
+	MessageText string `json:"message_text"`
+	ReplacedAt  string `json:"replaced_at"`
+}
+
+type Reaction struct {
+	Emoji       string `json:"emoji"`
+	Count       int    `json:"count"`
+	ReactedByMe bool   `json:"reacted_by_me"`
+}
+
+type ReplyPreview struct {
 	ID         int64  `json:"id"`
-	Content    string `json:"content"`
 	SenderID   int64  `json:"sender_id"`
 	SenderName string `json:"sender_name"`
-	CreatedAt  string `json:"created_at"`
-	IsRead     bool   `json:"is_read"`
+	Content    string `json:"content"`
 }
 
 func (s *MessageSRV) GetByID(ctx context.Context, id int64) (*Message, error) {
@@ -64,49 +166,386 @@ func (s *MessageSRV) GetByID(ctx context.Context, id int64) (*Message, error) {
 	}
 
 	return &Message{
-		ID:          message.ID,
-		ChatID:      message.ChatID,
-		SenderID:    message.SenderID,
-		MessageText: message.MessageText,
-		IsRead:      message.IsRead,
-		CreatedAt:   message.CreatedAt,
-		UpdatedAt:   message.UpdatedAt,
+		ID:               message.ID,
+		ChatID:           message.ChatID,
+		SenderID:         message.SenderID,
+		MessageText:      message.MessageText,
+		IsRead:           message.IsRead,
+		CreatedAt:        message.CreatedAt,
+		UpdatedAt:        message.UpdatedAt,
+		ReplyToMessageID: message.ReplyToID,
+		EditedAt:         message.EditedAt,
+		IsEdited:         message.EditedAt != nil,
+		DeletedAt:        message.DeletedAt,
+		IsDeleted:        message.DeletedAt != nil,
 	}, nil
 }
 
-func (s *MessageSRV) GetByChatID(ctx context.Context, chatID int64) ([]MessageDetail, error) {
+type MessagePage struct {
+	Messages   []MessageDetail `json:"messages"`
+	PrevCursor *int64          `json:"prev_cursor"`
+	NextCursor *int64          `json:"next_cursor"`
+}
+
+func (s *MessageSRV) GetByChatID(ctx context.Context, chatID, viewerID int64, cq *store.CursorQuery) (*MessagePage, error) {
+	page, err := s.repo.MessageStorage.GetMessages(ctx, chatID, viewerID, cq)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toMessagePage(ctx, page, viewerID)
+}
+
+func (s *MessageSRV) GetThread(ctx context.Context, rootID, viewerID int64, cq *store.CursorQuery) (*MessagePage, error) {
+	page, err := s.repo.MessageStorage.GetThread(ctx, rootID, viewerID, cq)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toMessagePage(ctx, page, viewerID)
+}
+
+func (s *MessageSRV) toMessagePage(ctx context.Context, page *store.MessagePage, viewerID int64) (*MessagePage, error) {
+	msgIDs := make([]int64, len(page.Messages))
+	for i, msg := range page.Messages {
+		msgIDs[i] = msg.ID
+	}
+
+	reactions, err := s.repo.ReactionStorage.GetSummaries(ctx, msgIDs, viewerID)
+	if err != nil {
+		return nil, err
+	}
 
-	var messags []MessageDetail
+	attachments, err := s.loadAttachments(ctx, msgIDs)
+	if err != nil {
+		return nil, err
+	}
 
-	messag, err := s.repo.MessageStorage.GetMessages(ctx, chatID)
+	mentions, err := s.repo.MentionStorage.GetByMessageIDs(ctx, msgIDs)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, msg := range messag {
+	messags := []MessageDetail{}
+
+	for _, msg := range page.Messages {
 		mes := MessageDetail{
-			ID:         msg.ID,
-			Content:    msg.Content,
-			SenderID:   msg.SenderID,
-			SenderName: msg.SenderName,
-			CreatedAt:  msg.CreatedAt,
-			IsRead:     msg.IsRead,
+			ID:          msg.ID,
+			Content:     msg.Content,
+			SenderID:    msg.SenderID,
+			SenderName:  msg.SenderName,
+			CreatedAt:   msg.CreatedAt,
+			IsRead:      msg.IsRead,
+			ReplyCount:  msg.ReplyCount,
+			Reactions:   []Reaction{},
+			Attachments: []Attachment{},
+			Mentions:    []Mention{},
+			EditedAt:    msg.EditedAt,
+			IsEdited:    msg.EditedAt != nil,
+			DeletedAt:   msg.DeletedAt,
+			IsDeleted:   msg.DeletedAt != nil,
+		}
+		if msg.ReplyTo != nil {
+			mes.ReplyTo = &ReplyPreview{
+				ID:         msg.ReplyTo.ID,
+				SenderID:   msg.ReplyTo.SenderID,
+				SenderName: msg.ReplyTo.SenderName,
+				Content:    msg.ReplyTo.Content,
+			}
+		}
+		for _, r := range reactions[msg.ID] {
+			mes.Reactions = append(mes.Reactions, Reaction{
+				Emoji:       r.Emoji,
+				Count:       r.Count,
+				ReactedByMe: r.ReactedByMe,
+			})
+		}
+		mes.Attachments = append(mes.Attachments, attachments[msg.ID]...)
+		for _, m := range mentions[msg.ID] {
+			mes.Mentions = append(mes.Mentions, Mention{UserID: m.UserID, Username: m.Username})
 		}
 
 		messags = append(messags, mes)
 	}
 
-	return messags, nil
+	return &MessagePage{
+		Messages:   messags,
+		PrevCursor: page.PrevCursor,
+		NextCursor: page.NextCursor,
+	}, nil
 }
 
-func (s *MessageSRV) MarkChatAsRead(ctx context.Context, chatID, userID int64) error {
-	return s.repo.MessageStorage.MarkAsRead(ctx, chatID, userID)
+// MarkChatAsRead - chatni o'qilgan deb belgilaydi va oxirgi o'qilgan xabar ID sini qaytaradi
+func (s *MessageSRV) MarkChatAsRead(ctx context.Context, chatID, userID int64) (int64, error) {
+	var lastReadID int64
+
+	err := s.repo.UnitOfWork.Do(ctx, func(ctx context.Context, repos *store.Storage) error {
+		id, err := repos.MessageStorage.MarkAsRead(ctx, chatID, userID)
+		if err != nil {
+			return err
+		}
+		lastReadID = id
+
+		return enqueue(ctx, repos, OutboxMessagesRead, MessagesReadEvent{
+			ChatID:     chatID,
+			ReaderID:   userID,
+			LastReadID: lastReadID,
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return lastReadID, nil
 }
 
-func (s *MessageSRV) UpdateMessage(ctx context.Context, msgID, userID int64, newText string) error {
-	return s.repo.MessageStorage.Update(ctx, msgID, userID, newText)
+// UpdateMessage - eski matnni revision sifatida saqlab, xabarni yangilaydi.
+// Tahrirlangan vaqt (edited_at) qaytariladi.
+func (s *MessageSRV) UpdateMessage(ctx context.Context, msgID, userID int64, newText string) (string, error) {
+	var editedAt string
+
+	err := s.repo.UnitOfWork.Do(ctx, func(ctx context.Context, repos *store.Storage) error {
+		if err := repos.MessageStorage.CreateRevision(ctx, msgID, userID); err != nil {
+			return err
+		}
+
+		t, err := repos.MessageStorage.Update(ctx, msgID, userID, newText)
+		if err != nil {
+			return err
+		}
+		editedAt = t
+
+		msg, err := repos.MessageStorage.GetByID(ctx, msgID)
+		if err != nil {
+			return err
+		}
+
+		return enqueue(ctx, repos, OutboxMessageUpdated, MessageUpdatedEvent{
+			ChatID:      msg.ChatID,
+			MessageID:   msgID,
+			MessageText: newText,
+			EditedAt:    editedAt,
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return editedAt, nil
 }
 
-func (s *MessageSRV) DeleteMessage(ctx context.Context, msgID, userID int64) error {
-	return s.repo.MessageStorage.Delete(ctx, msgID, userID)
+func (s *MessageSRV) GetRevisions(ctx context.Context, msgID int64) ([]MessageRevision, error) {
+	revisions, err := s.repo.MessageStorage.GetRevisions(ctx, msgID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]MessageRevision, len(revisions))
+	for i, rev := range revisions {
+		result[i] = MessageRevision{
+			ID:          rev.ID,
+			MessageText: rev.MessageText,
+			ReplacedAt:  rev.CreatedAt,
+		}
+	}
+
+	return result, nil
+}
+
+// DeleteMessage - xabarni hamma uchun o'chiradi (tombstone qoladi).
+// Yuboruvchi faqat window ichida o'chira oladi (window = 0 bo'lsa cheklov yo'q),
+// group owner/admin esa istalgan xabarni istalgan vaqtda moderatsiya qiladi.
+func (s *MessageSRV) DeleteMessage(ctx context.Context, msgID, actorID int64, window time.Duration) error {
+	msg, err := s.repo.MessageStorage.GetByID(ctx, msgID)
+	if err != nil {
+		return err
+	}
+	if msg.DeletedAt != nil {
+		return store.SqlNotfound
+	}
+
+	if msg.SenderID != actorID || !withinWindow(msg.CreatedAt, window) {
+		role, err := s.repo.MemberStorage.GetRole(ctx, msg.ChatID, actorID)
+		if err != nil {
+			if errors.Is(err, store.SqlNotfound) {
+				return store.SqlForbidden
+			}
+			return err
+		}
+		if role != RoleOwner && role != RoleAdmin {
+			if msg.SenderID == actorID {
+				return ErrDeleteWindowExpired
+			}
+			return store.SqlForbidden
+		}
+	}
+
+	var blobKeys []string
+	err = s.repo.UnitOfWork.Do(ctx, func(ctx context.Context, repos *store.Storage) error {
+		if err := repos.MessageStorage.Delete(ctx, msgID, actorID); err != nil {
+			return err
+		}
+
+		if err := repos.ReactionStorage.DeleteByMessage(ctx, msgID); err != nil {
+			return err
+		}
+
+		if err := repos.MentionStorage.DeleteByMessage(ctx, msgID); err != nil {
+			return err
+		}
+
+		keys, err := repos.AttachmentStorage.DeleteByMessage(ctx, msgID)
+		if err != nil {
+			return err
+		}
+		blobKeys = keys
+
+		if err := repos.MessageStorage.DeleteRevisions(ctx, msgID); err != nil {
+			return err
+		}
+
+		return enqueue(ctx, repos, OutboxMessageDeleted, MessageDeletedEvent{ChatID: msg.ChatID, MessageID: msgID})
+	})
+	if err != nil {
+		return err
+	}
+
+	// Fayllar transaction commit bo'lgandan keyin o'chiriladi
+	s.deleteBlobs(ctx, blobKeys)
+
+	return nil
+}
+
+// HideMessage - xabarni faqat shu user uchun yashiradi.
+// Event userning boshqa qurilmalari ham xabarni yashirishi uchun yoziladi.
+func (s *MessageSRV) HideMessage(ctx context.Context, msgID, userID int64) error {
+	return s.repo.UnitOfWork.Do(ctx, func(ctx context.Context, repos *store.Storage) error {
+		if err := repos.MessageStorage.Hide(ctx, msgID, userID); err != nil {
+			return err
+		}
+
+		msg, err := repos.MessageStorage.GetByID(ctx, msgID)
+		if err != nil {
+			return err
+		}
+
+		return enqueue(ctx, repos, OutboxMessageHidden, MessageHiddenEvent{
+			ChatID:    msg.ChatID,
+			MessageID: msgID,
+			UserID:    userID,
+		})
+	})
+}
+
+func withinWindow(createdAt string, window time.Duration) bool {
+	if window <= 0 {
+		return true
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return false
+	}
+
+	return time.Since(t) <= window
+}
+
+func (s *MessageSRV) AddReaction(ctx context.Context, msgID, userID int64, emoji string) error {
+	return s.repo.UnitOfWork.Do(ctx, func(ctx context.Context, repos *store.Storage) error {
+		if err := repos.ReactionStorage.Add(ctx, &store.Reaction{
+			MessageID: msgID,
+			UserID:    userID,
+			Emoji:     emoji,
+		}); err != nil {
+			return err
+		}
+
+		msg, err := repos.MessageStorage.GetByID(ctx, msgID)
+		if err != nil {
+			return err
+		}
+		user, err := repos.UserStore.GetUserByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		return enqueue(ctx, repos, OutboxReactionAdded, ReactionEvent{
+			ChatID:    msg.ChatID,
+			MessageID: msgID,
+			UserID:    userID,
+			Username:  user.UserName,
+			Emoji:     emoji,
+		})
+	})
+}
+
+func (s *MessageSRV) RemoveReaction(ctx context.Context, msgID, userID int64, emoji string) error {
+	return s.repo.UnitOfWork.Do(ctx, func(ctx context.Context, repos *store.Storage) error {
+		if err := repos.ReactionStorage.Remove(ctx, msgID, userID, emoji); err != nil {
+			return err
+		}
+
+		msg, err := repos.MessageStorage.GetByID(ctx, msgID)
+		if err != nil {
+			return err
+		}
+
+		return enqueue(ctx, repos, OutboxReactionRemoved, ReactionEvent{
+			ChatID:    msg.ChatID,
+			MessageID: msgID,
+			UserID:    userID,
+			Emoji:     emoji,
+		})
+	})
+}
+
+type ExpiredMessage struct {
+	ID     int64
+	ChatID int64
+}
+
+// DeleteExpired - sweeper uchun: muddati o'tgan xabarlarni o'chirib, ularni qaytaradi.
+// Attachment fayllari transaction commit bo'lgandan keyin o'chiriladi.
+func (s *MessageSRV) DeleteExpired(ctx context.Context, limit int) ([]ExpiredMessage, error) {
+	var result []ExpiredMessage
+	var blobKeys []string
+
+	err := s.repo.UnitOfWork.Do(ctx, func(ctx context.Context, repos *store.Storage) error {
+		expired, err := repos.MessageStorage.LockExpired(ctx, limit)
+		if err != nil || len(expired) == 0 {
+			return err
+		}
+
+		ids := make([]int64, len(expired))
+		for i, m := range expired {
+			ids[i] = m.ID
+		}
+
+		keys, err := repos.AttachmentStorage.DeleteByMessages(ctx, ids)
+		if err != nil {
+			return err
+		}
+		blobKeys = keys
+
+		if err := repos.MessageStorage.DeleteByIDs(ctx, ids); err != nil {
+			return err
+		}
+
+		result = make([]ExpiredMessage, len(expired))
+		for i, m := range expired {
+			result[i] = ExpiredMessage{ID: m.ID, ChatID: m.ChatID}
+
+			if err := enqueue(ctx, repos, OutboxMessageDeleted, MessageDeletedEvent{ChatID: m.ChatID, MessageID: m.ID}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.deleteBlobs(ctx, blobKeys)
+
+	return result, nil
 }
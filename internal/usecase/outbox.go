@@ -0,0 +1,145 @@
+package service
+
+import (
+	"chatX/internal/store"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Outbox event turlari. Har biri o'zgarish bilan bitta transaction'da yoziladi va
+// relay orqali hub'ga yuboriladi. Typing, presence va delivered event'lari vaqtinchalik,
+// ular outbox'dan o'tmaydi.
+const (
+	OutboxMessageCreated  = "message_created"
+	OutboxMessageUpdated  = "message_updated"
+	OutboxMessageDeleted  = "message_deleted"
+	OutboxMessageHidden   = "message_hidden"
+	OutboxMessagesRead    = "messages_read"
+	OutboxReactionAdded   = "reaction_added"
+	OutboxReactionRemoved = "reaction_removed"
+	OutboxMemberAdded     = "member_added"
+	OutboxMemberRemoved   = "member_removed"
+	OutboxChatDeleted     = "chat_deleted"
+)
+
+// message_created payload'i - Message'ning o'zi
+
+type MessageUpdatedEvent struct {
+	ChatID      int64  `json:"chat_id"`
+	MessageID   int64  `json:"message_id"`
+	MessageText string `json:"message_text"`
+	EditedAt    string `json:"edited_at"`
+}
+
+type MessageDeletedEvent struct {
+	ChatID    int64 `json:"chat_id"`
+	MessageID int64 `json:"message_id"`
+}
+
+type MessageHiddenEvent struct {
+	ChatID    int64 `json:"chat_id"`
+	MessageID int64 `json:"message_id"`
+	UserID    int64 `json:"user_id"`
+}
+
+type MessagesReadEvent struct {
+	ChatID     int64 `json:"chat_id"`
+	ReaderID   int64 `json:"reader_id"`
+	LastReadID int64 `json:"last_read_id"`
+}
+
+type ReactionEvent struct {
+	ChatID    int64  `json:"chat_id"`
+	MessageID int64  `json:"message_id"`
+	UserID    int64  `json:"user_id"`
+	Username  string `json:"username,omitempty"`
+	Emoji     string `json:"emoji"`
+}
+
+type MemberEvent struct {
+	ChatID    int64  `json:"chat_id"`
+	UserID    int64  `json:"user_id"`
+	ActorID   int64  `json:"actor_id"`
+	Username  string `json:"username,omitempty"`
+	ActorName string `json:"actor_name,omitempty"`
+}
+
+// ChatDeletedEvent - a'zolar o'chirishdan oldin yoziladi: commit'dan keyin ular bazada bo'lmaydi
+type ChatDeletedEvent struct {
+	ChatID        int64   `json:"chat_id"`
+	DeletedByID   int64   `json:"deleted_by_id"`
+	DeletedByName string  `json:"deleted_by_name"`
+	MemberIDs     []int64 `json:"member_ids"`
+}
+
+// enqueue - event'ni joriy transaction ichida outbox'ga yozadi
+func enqueue(ctx context.Context, repos *store.Storage, eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return repos.OutboxStorage.Add(ctx, eventType, data)
+}
+
+type OutboxSRV struct {
+	repo *store.Storage
+}
+
+// outboxLease - band qilingan batch shu vaqt ichida yuborilishi kerak; relay qulasa batch
+// lease tugagach boshqa relay'ga beriladi
+const outboxLease = time.Minute
+
+// Relay - yuborilmagan event'larni band qilib, transaction'dan tashqarida publish'ga beradi.
+// Birinchi xatoda to'xtaydi: faqat yuborilganlar belgilanadi, qolganlari keyingi urinishda
+// shu tartibda qayta yuboriladi (at-least-once), shuning uchun publish takrorga chidamli bo'lishi kerak.
+// Lock boshqa instance'da yoki oldingi batch hali yuborilayotgan bo'lsa 0 qaytadi.
+func (s *OutboxSRV) Relay(ctx context.Context, limit int, publish func(entry store.OutboxEntry) error) (int, error) {
+	var entries []store.OutboxEntry
+
+	err := s.repo.UnitOfWork.Do(ctx, func(ctx context.Context, repos *store.Storage) error {
+		locked, err := repos.OutboxStorage.TryLock(ctx)
+		if err != nil || !locked {
+			return err
+		}
+
+		entries, err = repos.OutboxStorage.Claim(ctx, limit, outboxLease)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	published := make([]int64, 0, len(entries))
+	var publishErr error
+	for _, entry := range entries {
+		if err := publish(entry); err != nil {
+			publishErr = fmt.Errorf("outbox event %d (%s): %w", entry.ID, entry.EventType, err)
+			break
+		}
+		published = append(published, entry.ID)
+	}
+
+	if err := s.repo.OutboxStorage.MarkPublished(ctx, published); err != nil {
+		return 0, err
+	}
+
+	if publishErr != nil {
+		pending := make([]int64, 0, len(entries)-len(published))
+		for _, entry := range entries[len(published):] {
+			pending = append(pending, entry.ID)
+		}
+		if err := s.repo.OutboxStorage.Release(ctx, pending); err != nil {
+			return len(published), errors.Join(publishErr, err)
+		}
+		return len(published), publishErr
+	}
+
+	return len(published), nil
+}
+
+func (s *OutboxSRV) Prune(ctx context.Context, retention time.Duration, limit int) (int64, error) {
+	return s.repo.OutboxStorage.Prune(ctx, retention, limit)
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"chatX/internal/store"
+	"context"
+)
+
+type PresenceSRV struct {
+	repo *store.Storage
+}
+
+// MarkOffline - user'ning oxirgi ulanishi yopilganda last_seen_at ni yozadi.
+// Qaytgan qiymat boshqalarga ko'rinadigan vaqt (yashirilgan bo'lsa nil).
+func (s *PresenceSRV) MarkOffline(ctx context.Context, userID int64) (*string, error) {
+	return s.repo.PresenceStorage.UpdateLastSeen(ctx, userID)
+}
+
+func (s *PresenceSRV) GetLastSeen(ctx context.Context, userIDs []int64) (map[int64]*string, error) {
+	return s.repo.PresenceStorage.GetLastSeen(ctx, userIDs)
+}
+
+func (s *PresenceSRV) GetContactIDs(ctx context.Context, userID int64) ([]int64, error) {
+	return s.repo.PresenceStorage.GetContactIDs(ctx, userID)
+}
+
+func (s *PresenceSRV) SetHideLastSeen(ctx context.Context, userID int64, hide bool) error {
+	return s.repo.PresenceStorage.SetHideLastSeen(ctx, userID, hide)
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"chatX/internal/store"
+	"context"
+)
+
+const (
+	ReceiptSent      = "sent"
+	ReceiptDelivered = "delivered"
+	ReceiptRead      = "read"
+)
+
+type Receipt struct {
+	UserID      int64   `json:"user_id"`
+	Username    string  `json:"username"`
+	Status      string  `json:"status"`
+	DeliveredAt *string `json:"delivered_at"`
+	ReadAt      *string `json:"read_at"`
+}
+
+// MarkDelivered - xabarlar user client'lariga yetkazilganini yozadi, birinchi marta yozilganlarini qaytaradi
+func (s *MessageSRV) MarkDelivered(ctx context.Context, deliveries []store.Delivery) ([]store.Delivery, error) {
+	return s.repo.ReceiptStorage.MarkDelivered(ctx, deliveries)
+}
+
+// GetReceipts - xabarni kim qabul qilgani va o'qiganini vaqtlari bilan qaytaradi
+func (s *MessageSRV) GetReceipts(ctx context.Context, msgID int64) ([]Receipt, error) {
+	receipts, err := s.repo.ReceiptStorage.GetByMessageID(ctx, msgID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Receipt, len(receipts))
+	for i, r := range receipts {
+		status := ReceiptSent
+		switch {
+		case r.ReadAt != nil:
+			status = ReceiptRead
+		case r.DeliveredAt != nil:
+			status = ReceiptDelivered
+		}
+
+		result[i] = Receipt{
+			UserID:      r.UserID,
+			Username:    r.Username,
+			Status:      status,
+			DeliveredAt: r.DeliveredAt,
+			ReadAt:      r.ReadAt,
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"chatX/internal/store"
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrSendAtInPast = errors.New("send_at must be in the future")
+
+type ScheduledMessage struct {
+	ID               int64  `json:"id"`
+	ChatID           int64  `json:"chat_id"`
+	MessageText      string `json:"message_text"`
+	ReplyToMessageID *int64 `json:"reply_to_message_id"`
+	SendAt           string `json:"send_at"`
+	Status           string `json:"status"`
+	CreatedAt        string `json:"created_at"`
+}
+
+// Schedule - xabarni send_at vaqtida yuborish uchun pending holatda saqlaydi
+func (s *MessageSRV) Schedule(ctx context.Context, msg Message, sendAt time.Time) (*ScheduledMessage, error) {
+	if !sendAt.After(time.Now()) {
+		return nil, ErrSendAtInPast
+	}
+
+	if err := s.validateReply(ctx, msg.ChatID, msg.ReplyToMessageID); err != nil {
+		return nil, err
+	}
+
+	req := store.ScheduledMessage{
+		ChatID:      msg.ChatID,
+		SenderID:    msg.SenderID,
+		MessageText: msg.MessageText,
+		ReplyToID:   msg.ReplyToMessageID,
+		SendAt:      sendAt,
+	}
+
+	if err := s.repo.ScheduledMessageStorage.Create(ctx, &req); err != nil {
+		return nil, err
+	}
+
+	return toScheduledMessage(req), nil
+}
+
+func (s *MessageSRV) GetScheduled(ctx context.Context, userID int64) ([]ScheduledMessage, error) {
+	pending, err := s.repo.ScheduledMessageStorage.GetPendingByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ScheduledMessage, len(pending))
+	for i, msg := range pending {
+		result[i] = *toScheduledMessage(msg)
+	}
+
+	return result, nil
+}
+
+func (s *MessageSRV) CancelScheduled(ctx context.Context, id, userID int64) error {
+	return s.repo.ScheduledMessageStorage.Cancel(ctx, id, userID)
+}
+
+// DispatchScheduled - vaqti kelgan xabarlarni oddiy xabar sifatida yaratadi.
+// Hammasi bitta transaction ichida: commit bo'lmasa xabarlar pending bo'lib qoladi
+// va keyingi urinishda (server restartdan keyin ham) qayta yuboriladi.
+func (s *MessageSRV) DispatchScheduled(ctx context.Context, limit int) ([]*Message, error) {
+	var sent []*Message
+
+	err := s.repo.UnitOfWork.Do(ctx, func(ctx context.Context, repos *store.Storage) error {
+		due, err := repos.ScheduledMessageStorage.LockDue(ctx, limit)
+		if err != nil {
+			return err
+		}
+
+		for _, scheduled := range due {
+			isMember, err := repos.MemberStorage.IsMember(ctx, scheduled.ChatID, scheduled.SenderID)
+			if err != nil {
+				return err
+			}
+			if !isMember {
+				if err := repos.ScheduledMessageStorage.MarkProcessed(ctx, scheduled.ID, store.ScheduledFailed, nil); err != nil {
+					return err
+				}
+				continue
+			}
+
+			message, err := repos.MessageStorage.Create(ctx, &store.Message{
+				ChatID:      scheduled.ChatID,
+				SenderID:    scheduled.SenderID,
+				MessageText: scheduled.MessageText,
+				ReplyToID:   scheduled.ReplyToID,
+			})
+			if err != nil {
+				return err
+			}
+
+			mentions, err := saveMentions(ctx, repos, message)
+			if err != nil {
+				return err
+			}
+
+			if err := repos.ScheduledMessageStorage.MarkProcessed(ctx, scheduled.ID, store.ScheduledSent, &message.ID); err != nil {
+				return err
+			}
+
+			msg := toMessage(message)
+			msg.Mentions = mentions
+			if err := enqueue(ctx, repos, OutboxMessageCreated, msg); err != nil {
+				return err
+			}
+			sent = append(sent, msg)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sent, nil
+}
+
+func toScheduledMessage(msg store.ScheduledMessage) *ScheduledMessage {
+	return &ScheduledMessage{
+		ID:               msg.ID,
+		ChatID:           msg.ChatID,
+		MessageText:      msg.MessageText,
+		ReplyToMessageID: msg.ReplyToID,
+		SendAt:           msg.SendAt.Format(time.RFC3339),
+		Status:           msg.Status,
+		CreatedAt:        msg.CreatedAt,
+	}
+}
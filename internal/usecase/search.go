@@ -0,0 +1,53 @@
+package service
+
+import (
+	"chatX/internal/store"
+	"context"
+	"fmt"
+)
+
+type SearchResult struct {
+	MessageID  int64   `json:"message_id"`
+	ChatID     int64   `json:"chat_id"`
+	ChatName   string  `json:"chat_name"`
+	SenderID   int64   `json:"sender_id"`
+	SenderName string  `json:"sender_name"`
+	Snippet    string  `json:"snippet"`
+	Rank       float32 `json:"rank"`
+	CreatedAt  string  `json:"created_at"`
+	// ContextURL - xabar atrofidagi tarixni ochish uchun (around view)
+	ContextURL string `json:"context_url"`
+}
+
+type SearchPage struct {
+	Results    []SearchResult `json:"results"`
+	NextCursor *string        `json:"next_cursor"`
+}
+
+// SearchMessages - faqat viewer a'zo bo'lgan chatlar ichida qidiradi
+func (s *MessageSRV) SearchMessages(ctx context.Context, viewerID int64, sq *store.SearchQuery) (*SearchPage, error) {
+	page, err := s.repo.MessageStorage.Search(ctx, viewerID, sq)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, len(page.Results))
+	for i, r := range page.Results {
+		results[i] = SearchResult{
+			MessageID:  r.MessageID,
+			ChatID:     r.ChatID,
+			ChatName:   r.ChatName,
+			SenderID:   r.SenderID,
+			SenderName: r.SenderName,
+			Snippet:    r.Snippet,
+			Rank:       r.Rank,
+			CreatedAt:  r.CreatedAt,
+			ContextURL: fmt.Sprintf("/api/v1/chats/%d/messages?around=%d", r.ChatID, r.MessageID),
+		}
+	}
+
+	return &SearchPage{
+		Results:    results,
+		NextCursor: page.NextCursor,
+	}, nil
+}
@@ -1,8 +1,10 @@
 package service
 
 import (
+	"chatX/internal/blob"
 	"chatX/internal/store"
 	"context"
+	"io"
 	"time"
 )
 
@@ -27,7 +29,8 @@ type Services struct {
 		CreateGroupChat(ctx context.Context, group *Group) (int64, error)
 		GetUserChats(ctx context.Context, userID int64, searchTerm string) ([]*ChatInfo, error)
 		Updatechat(ctx context.Context, group *Chatgroup) (*store.Group, error)
-		DeleteChat(ctx context.Context, chatID int) error
+		DeleteChat(ctx context.Context, actor *store.User, chatID int) error
+		SetMessageTTL(ctx context.Context, actorUserID, chatID int64, ttlSeconds int) error
 	}
 
 	MemberSRV interface {
@@ -40,18 +43,64 @@ type Services struct {
 	MessageSRV interface {
 		Create(ctx context.Context, msg Message) (*Message, error)
 		GetByID(ctx context.Context, id int64) (*Message, error)
-		GetByChatID(ctx context.Context, chatID int64) ([]MessageDetail, error)
-		MarkChatAsRead(ctx context.Context, chatID, userID int64) error
-		UpdateMessage(ctx context.Context, msgID, userID int64, newText string) error
-		DeleteMessage(ctx context.Context, msgID, userID int64) error
+		GetByChatID(ctx context.Context, chatID, viewerID int64, cq *store.CursorQuery) (*MessagePage, error)
+		GetThread(ctx context.Context, rootID, viewerID int64, cq *store.CursorQuery) (*MessagePage, error)
+		MarkChatAsRead(ctx context.Context, chatID, userID int64) (int64, error)
+		UpdateMessage(ctx context.Context, msgID, userID int64, newText string) (string, error)
+		GetRevisions(ctx context.Context, msgID int64) ([]MessageRevision, error)
+		DeleteMessage(ctx context.Context, msgID, actorID int64, window time.Duration) error
+		HideMessage(ctx context.Context, msgID, userID int64) error
+		AddReaction(ctx context.Context, msgID, userID int64, emoji string) error
+		RemoveReaction(ctx context.Context, msgID, userID int64, emoji string) error
+		Schedule(ctx context.Context, msg Message, sendAt time.Time) (*ScheduledMessage, error)
+		GetScheduled(ctx context.Context, userID int64) ([]ScheduledMessage, error)
+		CancelScheduled(ctx context.Context, id, userID int64) error
+		DispatchScheduled(ctx context.Context, limit int) ([]*Message, error)
+		DeleteExpired(ctx context.Context, limit int) ([]ExpiredMessage, error)
+		DeleteOrphanUploads(ctx context.Context, olderThan time.Duration, limit int) (int, error)
+		UploadAttachment(ctx context.Context, chatID, uploaderID int64, fileName, mimeType string, r io.Reader) (*Attachment, error)
+		GetAttachment(ctx context.Context, id int64) (*StoredAttachment, error)
+		OpenAttachment(ctx context.Context, id int64) (io.ReadCloser, error)
+		OpenThumbnail(ctx context.Context, id int64, size string) (io.ReadCloser, error)
+		NextUnreadMention(ctx context.Context, chatID, userID, afterID int64) (int64, error)
+		SearchMessages(ctx context.Context, viewerID int64, sq *store.SearchQuery) (*SearchPage, error)
+		MarkDelivered(ctx context.Context, deliveries []store.Delivery) ([]store.Delivery, error)
+		GetReceipts(ctx context.Context, msgID int64) ([]Receipt, error)
+	}
+
+	PresenceSRV interface {
+		MarkOffline(ctx context.Context, userID int64) (*string, error)
+		GetLastSeen(ctx context.Context, userIDs []int64) (map[int64]*string, error)
+		GetContactIDs(ctx context.Context, userID int64) ([]int64, error)
+		SetHideLastSeen(ctx context.Context, userID int64, hide bool) error
+	}
+
+	EventSRV interface {
+		Append(ctx context.Context, userIDs []int64, eventType string, payload []byte) (map[int64]int64, error)
+		Sync(ctx context.Context, userID, since int64, limit int) (*EventPage, error)
+		Prune(ctx context.Context, retention time.Duration, limit int) (int64, error)
+	}
+
+	OutboxSRV interface {
+		Relay(ctx context.Context, limit int, publish func(entry store.OutboxEntry) error) (int, error)
+		Prune(ctx context.Context, retention time.Duration, limit int) (int64, error)
+	}
+
+	TicketSRV interface {
+		Issue(ctx context.Context, userID int64, ip string, ttl time.Duration) (*IssuedTicket, error)
+		Redeem(ctx context.Context, ticket, ip string) (int64, error)
 	}
 }
 
-func NewServices(repo *store.Storage) *Services {
+func NewServices(repo *store.Storage, blobs blob.Store) *Services {
 	return &Services{
-		UserSrvc:   &UserSrvc{repo},
-		ChatSRVC:   &ChatSRVC{repo},
-		MemberSRV:  &MemberSRV{repo},
-		MessageSRV: &MessageSRV{repo},
+		UserSrvc:    &UserSrvc{repo},
+		ChatSRVC:    &ChatSRVC{repo: repo, blob: blobs},
+		MemberSRV:   &MemberSRV{repo},
+		MessageSRV:  &MessageSRV{repo: repo, blob: blobs},
+		PresenceSRV: &PresenceSRV{repo},
+		EventSRV:    &EventSRV{repo},
+		OutboxSRV:   &OutboxSRV{repo},
+		TicketSRV:   &TicketSRV{repo},
 	}
 }
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"chatX/internal/store"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidTicket = errors.New("invalid or expired connection ticket")
+
+type TicketSRV struct {
+	repo *store.Storage
+}
+
+// IssuedTicket - client'ga qaytariladigan ticket; bazada faqat hash'i saqlanadi
+type IssuedTicket struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Issue - userga ttl davomida amal qiladigan bir martalik ticket beradi.
+// ip berilsa ticket faqat shu IP'dan ishlatiladi.
+func (s *TicketSRV) Issue(ctx context.Context, userID int64, ip string, ttl time.Duration) (*IssuedTicket, error) {
+	// Ishlatilmay qolgan ticket'lar yangilari berilganda tozalanadi
+	if _, err := s.repo.TicketStorage.DeleteExpired(ctx); err != nil {
+		return nil, err
+	}
+
+	plain := uuid.New().String()
+	ticket := &store.Ticket{
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if ip != "" {
+		ticket.IP = &ip
+	}
+
+	if err := s.repo.TicketStorage.Create(ctx, hashTicket(plain), ticket); err != nil {
+		return nil, err
+	}
+
+	return &IssuedTicket{Ticket: plain, ExpiresAt: ticket.ExpiresAt}, nil
+}
+
+// Redeem - ticket'ni ishlatadi va user ID'ni qaytaradi. Ticket boshqa IP'ga bog'langan bo'lsa ham o'chiriladi.
+func (s *TicketSRV) Redeem(ctx context.Context, plain, ip string) (int64, error) {
+	ticket, err := s.repo.TicketStorage.Consume(ctx, hashTicket(plain))
+	if err != nil {
+		if errors.Is(err, store.SqlNotfound) {
+			return 0, ErrInvalidTicket
+		}
+		return 0, err
+	}
+
+	if ticket.IP != nil && *ticket.IP != ip {
+		return 0, ErrInvalidTicket
+	}
+
+	return ticket.UserID, nil
+}
+
+func hashTicket(plain string) string {
+	hash := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,268 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// backplaneHeartbeat - instance o'zi tirikligini boshqa instance'larga shu oraliqda bildiradi
+	backplaneHeartbeat = 10 * time.Second
+	// instanceTTL - shu vaqt ichida xabar kelmagan instance o'chgan hisoblanadi va uning presence'i unutiladi
+	instanceTTL = 3 * backplaneHeartbeat
+)
+
+// Backplane - bir nechta API instance'ining hub'larini bog'laydi: bitta instance'dagi broadcast
+// boshqa instance'larga ulangan client'larga ham yetadi. Backplane nil bo'lsa hub faqat
+// o'z jarayoni ichida ishlaydi (bitta node uchun in-memory rejim).
+type Backplane interface {
+	// Publish - xabarni barcha instance'larga yuboradi (o'ziga ham qaytishi mumkin).
+	// Xabarlar Publish chaqirilgan tartibda yetkazilishi kerak.
+	Publish(msg []byte) error
+	// Subscribe - obuna o'rnatilgach qaytadi, kelgan xabarlar handler'ga ctx tugaguncha beriladi.
+	// handler(nil) - ulanish qayta tiklandi, orada xabarlar yo'qolgan bo'lishi mumkin.
+	Subscribe(ctx context.Context, handler func(msg []byte)) error
+}
+
+const (
+	kindBroadcast = "broadcast"
+	kindPresence  = "presence"
+	kindHello     = "hello"
+	kindSnapshot  = "snapshot"
+	kindHeartbeat = "heartbeat"
+	kindRoom      = "room"
+)
+
+// envelope - instance'lar orasida yuboriladigan xabar.
+// broadcast: Data frame'i Recipients'ga (Seqs va Deliveries bilan) yuboriladi.
+// presence: UserID'ning Origin instance'dagi holati Status'ga o'zgardi.
+// hello: yangi instance ishga tushdi, boshqalar snapshot bilan javob beradi.
+// snapshot: Origin instance'dagi barcha online/away userlar (Presence).
+// room: ChatID a'zolari o'zgardi, indeksdagi nusxa tashlab yuboriladi.
+type envelope struct {
+	Origin     string               `json:"origin"`
+	Kind       string               `json:"kind"`
+	Data       json.RawMessage      `json:"data,omitempty"`
+	Recipients []string             `json:"recipients,omitempty"`
+	Seqs       map[string]int64     `json:"seqs,omitempty"`
+	Deliveries map[string]*Delivery `json:"deliveries,omitempty"`
+	UserID     string               `json:"user_id,omitempty"`
+	Status     string               `json:"status,omitempty"`
+	Presence   map[string]string    `json:"presence,omitempty"`
+	ChatID     int64                `json:"chat_id,omitempty"`
+}
+
+// StartBackplane - backplane'ga obuna bo'ladi, boshqa instance'lardan presence holatini so'raydi
+// va heartbeat'larni boshlaydi. Backplane sozlanmagan bo'lsa hech narsa qilmaydi.
+func (h *Hub) StartBackplane(ctx context.Context) error {
+	if h.Backplane == nil {
+		return nil
+	}
+
+	if err := h.Backplane.Subscribe(ctx, h.handleRemote); err != nil {
+		return err
+	}
+
+	h.forward(envelope{Kind: kindHello})
+	go h.runHeartbeat(ctx)
+
+	return nil
+}
+
+// forward - xabarni boshqa instance'larga yuboradi (in-memory rejimda hech narsa qilmaydi).
+// Yo'qolsa keyingi heartbeat/snapshot tiklaydigan xabarlar uchun: xato faqat log qilinadi.
+func (h *Hub) forward(e envelope) {
+	if err := h.forwardErr(e); err != nil {
+		h.reportError("backplane publish failed", err)
+	}
+}
+
+// forwardErr - forward'ning xatoni qaytaradigan varianti: publish undan foydalanadi,
+// shunda boshqa instance'larga yetmagan event outbox'da qoladi va qayta yuboriladi
+func (h *Hub) forwardErr(e envelope) error {
+	if h.Backplane == nil {
+		return nil
+	}
+
+	e.Origin = h.InstanceID
+	msg, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("backplane message encoding failed: %w", err)
+	}
+
+	if err := h.Backplane.Publish(msg); err != nil {
+		return fmt.Errorf("backplane publish failed: %w", err)
+	}
+	return nil
+}
+
+// deliver - broadcast'ni shu instance'ga ulangan qabul qiluvchilarga yuboradi
+func (h *Hub) deliver(e *envelope) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, id := range e.Recipients {
+		out := Outbound{Data: e.Data, Delivery: e.Deliveries[id]}
+		if seq, ok := e.Seqs[id]; ok {
+			out.Data = WithSeq(e.Data, seq)
+			out.Seq = seq
+		}
+		h.sendToUser(id, out)
+	}
+}
+
+func (h *Hub) handleRemote(msg []byte) {
+	if msg == nil {
+		// Ulanish uzilib qolgan paytdagi presence o'zgarishlarini qayta olish uchun
+		h.forward(envelope{Kind: kindHello})
+		return
+	}
+
+	var e envelope
+	if err := json.Unmarshal(msg, &e); err != nil {
+		h.reportError("backplane message decoding failed", err)
+		return
+	}
+	if e.Origin == "" || e.Origin == h.InstanceID {
+		return
+	}
+
+	switch e.Kind {
+	case kindBroadcast:
+		h.deliver(&e)
+		h.touchInstance(e.Origin)
+	case kindPresence:
+		h.setRemotePresence(e.Origin, map[string]string{e.UserID: e.Status}, false)
+	case kindSnapshot:
+		h.setRemotePresence(e.Origin, e.Presence, true)
+	case kindHello:
+		h.touchInstance(e.Origin)
+		h.forward(envelope{Kind: kindSnapshot, Presence: h.localSnapshot()})
+	case kindHeartbeat:
+		h.touchInstance(e.Origin)
+	case kindRoom:
+		h.invalidateRoom(e.ChatID)
+		h.touchInstance(e.Origin)
+	}
+}
+
+func (h *Hub) runHeartbeat(ctx context.Context) {
+	ticker := time.NewTicker(backplaneHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			h.forward(envelope{Kind: kindHeartbeat})
+			h.expireInstances(now)
+		}
+	}
+}
+
+func (h *Hub) touchInstance(instanceID string) {
+	h.mu.Lock()
+	h.instances[instanceID] = time.Now()
+	h.mu.Unlock()
+}
+
+// setRemotePresence - boshqa instance'dagi userlar holatini yangilaydi.
+// replace bo'lsa shu instance'ning avvalgi yozuvlari snapshot bilan almashtiriladi.
+// Umumiy holat o'zgarishi haqida shu yerda xabar berilmaydi: buni o'zgarish yuz bergan instance qiladi.
+func (h *Hub) setRemotePresence(instanceID string, statuses map[string]string, replace bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.instances[instanceID] = time.Now()
+
+	if replace {
+		for userID, byInstance := range h.remotePresence {
+			if _, ok := statuses[userID]; !ok {
+				h.removeRemoteLocked(userID, byInstance, instanceID)
+			}
+		}
+	}
+
+	for userID, status := range statuses {
+		if status == PresenceOffline {
+			h.removeRemoteLocked(userID, h.remotePresence[userID], instanceID)
+			continue
+		}
+
+		byInstance, ok := h.remotePresence[userID]
+		if !ok {
+			byInstance = make(map[string]string)
+			h.remotePresence[userID] = byInstance
+		}
+		byInstance[instanceID] = status
+	}
+}
+
+// expireInstances - heartbeat yubormay qo'ygan instance'larning userlarini unutadi.
+// Umumiy holati o'zgargan userlar haqida faqat bitta (ID'si eng kichik tirik) instance xabar beradi.
+func (h *Hub) expireInstances(now time.Time) {
+	var changes []PresenceChange
+
+	h.mu.Lock()
+	for instanceID, seenAt := range h.instances {
+		if now.Sub(seenAt) < instanceTTL {
+			continue
+		}
+		delete(h.instances, instanceID)
+
+		for userID, byInstance := range h.remotePresence {
+			if _, ok := byInstance[instanceID]; !ok {
+				continue
+			}
+			before := h.presenceLocked(userID)
+			h.removeRemoteLocked(userID, byInstance, instanceID)
+			if after := h.presenceLocked(userID); after != before {
+				changes = append(changes, PresenceChange{UserID: userID, Status: after})
+			}
+		}
+	}
+	leader := true
+	for instanceID := range h.instances {
+		if instanceID < h.InstanceID {
+			leader = false
+			break
+		}
+	}
+	h.mu.Unlock()
+
+	if !leader {
+		return
+	}
+	for _, change := range changes {
+		h.reportPresence(change)
+	}
+}
+
+// removeRemoteLocked - chaqiruvchi h.mu ni ushlab turishi kerak
+func (h *Hub) removeRemoteLocked(userID string, byInstance map[string]string, instanceID string) {
+	delete(byInstance, instanceID)
+	if len(byInstance) == 0 {
+		delete(h.remotePresence, userID)
+	}
+}
+
+// localSnapshot - shu instance'ga ulangan userlarning holati
+func (h *Hub) localSnapshot() map[string]string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	snapshot := make(map[string]string, len(h.Clients))
+	for userID := range h.Clients {
+		snapshot[userID] = h.localPresenceLocked(userID)
+	}
+	return snapshot
+}
+
+func (h *Hub) reportError(msg string, err error) {
+	if h.OnError != nil {
+		h.OnError(msg, err)
+	}
+}
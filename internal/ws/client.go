@@ -1,14 +1,99 @@
 package ws
 
 import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
+// Config - ulanishlar uchun vaqt va hajm cheklovlari
+type Config struct {
+	// ReadTimeout - shu vaqt ichida client'dan hech narsa (pong ham) kelmasa ulanish o'lik hisoblanadi.
+	// Ping'lar shu vaqtning 9/10 qismida yuboriladi.
+	ReadTimeout time.Duration
+	// WriteTimeout - bitta frame'ni yozish uchun vaqt
+	WriteTimeout time.Duration
+	// MaxFrameSize - client yuboradigan bitta frame'ning maksimal hajmi (bayt)
+	MaxFrameSize int64
+	// SendBuffer - ulanish navbatidagi frame'lar soni; navbati to'lgan client uziladi
+	SendBuffer int
+}
+
+// DefaultConfig - 32KB frame'ga 4000 belgilik UTF-8 xabar matni va JSON konvert sig'adi
+func DefaultConfig() Config {
+	return Config{
+		ReadTimeout:  60 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		MaxFrameSize: 32 << 10,
+		SendBuffer:   256,
+	}
+}
+
+func (c Config) pingInterval() time.Duration {
+	return c.ReadTimeout * 9 / 10
+}
+
 type Client struct {
-	ID   string
-	Hub  *Hub
+	// ID - user ID; ConnID - shu ulanishning (qurilma/tab) unikal ID'si
+	ID     string
+	ConnID string
+	Hub    *Hub
+	// Conn - WebSocket ulanishi (SSE client'larda nil, ular SSEPump orqali o'qiladi)
 	Conn *websocket.Conn
-	Send chan []byte
+	Send chan Outbound
+	// Limiter - client buyruqlari uchun rate limit (nil bo'lsa cheklanmaydi)
+	Limiter *RateLimiter
+
+	// away - client o'zini away deb belgilagan (Hub.mu bilan himoyalangan)
+	away bool
+
+	// evicted - hub ulanishni uzishni so'raganda yopiladi; closeCode va closeReason undan oldin yoziladi
+	evicted     chan struct{}
+	evictOnce   sync.Once
+	closeCode   int
+	closeReason string
+}
+
+// NewClient - yangi ulanish; conn SSE client'lar uchun nil
+func NewClient(hub *Hub, userID string, conn *websocket.Conn) *Client {
+	return &Client{
+		ID:      userID,
+		ConnID:  uuid.NewString(),
+		Hub:     hub,
+		Conn:    conn,
+		Send:    make(chan Outbound, hub.Config.SendBuffer),
+		evicted: make(chan struct{}),
+	}
+}
+
+// Outbound - client'ga yuboriladigan frame. Delivery berilgan bo'lsa,
+// frame socket'ga muvaffaqiyatli yozilgach hub'ga "yetkazildi" signali beriladi.
+// Seq - Data ichidagi "seq" (saqlanmaydigan event'lar uchun 0).
+type Outbound struct {
+	Data     []byte
+	Seq      int64
+	Delivery *Delivery
+}
+
+type Delivery struct {
+	ChatID      int64
+	MessageID   int64
+	SenderID    string
+	RecipientID string
+}
+
+// evict - ulanishni close kodi va sababi bilan yopishni so'raydi. Faqat birinchi chaqiruvda true qaytadi.
+// Bloklanmaydi, shuning uchun h.mu ushlab turilganda ham chaqirish mumkin.
+func (c *Client) evict(code int, reason string) bool {
+	evicted := false
+	c.evictOnce.Do(func() {
+		c.closeCode, c.closeReason = code, reason
+		close(c.evicted)
+		evicted = true
+	})
+	return evicted
 }
 
 func (c *Client) ReadPump() {
@@ -17,24 +102,68 @@ func (c *Client) ReadPump() {
 		c.Conn.Close()
 	}()
 
+	cfg := c.Hub.Config
+	c.Conn.SetReadLimit(cfg.MaxFrameSize)
+	c.Conn.SetReadDeadline(time.Now().Add(cfg.ReadTimeout))
+	c.Conn.SetPongHandler(func(string) error {
+		return c.Conn.SetReadDeadline(time.Now().Add(cfg.ReadTimeout))
+	})
+
 	for {
-		_, _, err := c.Conn.ReadMessage()
+		msgType, data, err := c.Conn.ReadMessage()
 		if err != nil {
 			break
 		}
+		c.Conn.SetReadDeadline(time.Now().Add(cfg.ReadTimeout))
+
+		if msgType != websocket.TextMessage || c.Hub.OnFrame == nil {
+			continue
+		}
+
+		c.Hub.OnFrame(c, data)
 	}
 }
 
 func (c *Client) WritePump() {
+	cfg := c.Hub.Config
+	ping := time.NewTicker(cfg.pingInterval())
 	defer func() {
+		ping.Stop()
 		c.Conn.Close()
 	}()
 
-	for message := range c.Send {
-		if err := c.Conn.WriteMessage(websocket.TextMessage, message); err != nil {
+	for {
+		select {
+		case message, ok := <-c.Send:
+			if !ok {
+				c.writeClose(websocket.CloseNormalClosure, "")
+				return
+			}
+
+			c.Conn.SetWriteDeadline(time.Now().Add(cfg.WriteTimeout))
+			if err := c.Conn.WriteMessage(websocket.TextMessage, message.Data); err != nil {
+				return
+			}
+
+			if message.Delivery != nil {
+				c.Hub.reportDelivery(*message.Delivery)
+			}
+
+		case <-ping.C:
+			c.Conn.SetWriteDeadline(time.Now().Add(cfg.WriteTimeout))
+			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+
+		case <-c.evicted:
+			// Conn yopilgach ReadPump xato bilan chiqadi va client hub'dan o'chiriladi
+			c.writeClose(c.closeCode, c.closeReason)
 			return
 		}
 	}
+}
 
-	c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+func (c *Client) writeClose(code int, reason string) {
+	deadline := time.Now().Add(c.Hub.Config.WriteTimeout)
+	c.Conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), deadline)
 }
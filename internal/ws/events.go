@@ -0,0 +1,138 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// appendTimeout - event'ni oqimga yozish uchun vaqt
+const appendTimeout = 5 * time.Second
+
+// EventStore - hub yuboradigan muhim event'larni har bir user oqimiga ketma-ket seq bilan yozadi.
+// Client uzilib qolsa, oxirgi ko'rgan seq'dan keyingi event'larni shu oqimdan qayta oladi.
+type EventStore interface {
+	Append(ctx context.Context, userIDs []int64, eventType string, payload []byte) (map[int64]int64, error)
+}
+
+// WithSeq - saqlangan payload'ga {"seq":N,...} maydonini qo'shadi
+func WithSeq(payload []byte, seq int64) []byte {
+	payload = bytes.TrimSpace(payload)
+	if len(payload) < 2 || payload[0] != '{' {
+		return payload
+	}
+
+	out := make([]byte, 0, len(payload)+24)
+	out = append(out, `{"seq":`...)
+	out = strconv.AppendInt(out, seq, 10)
+	if !bytes.Equal(payload, []byte("{}")) {
+		out = append(out, ',')
+	}
+	return append(out, payload[1:]...)
+}
+
+// publishStripes - seq ajratish va yuborish tartibini qabul qiluvchi bo'yicha saqlaydigan lock'lar soni
+const publishStripes = 64
+
+// publish - event'ni qabul qiluvchilar oqimiga yozib, har biriga o'z seq raqami bilan yuboradi
+// (boshqa instance'larga ulanganlarga backplane orqali).
+// Faqat qabul qiluvchilarning lock'lari olinadi: bir userga boradigan event'lar seq tartibida yuboriladi,
+// boshqa userlarga boradigan broadcast'lar esa bir-birini kutmaydi.
+// Oqimga yozib bo'lmasa event yuborilmaydi va xato qaytadi: seq'siz event resume'ni buzadi.
+// Backplane xabarni qabul qilmasa ham xato qaytadi (masalan ErrBackplaneFull).
+// delivery berilgan bo'lsa har bir qabul qiluvchi uchun "yetkazildi" signali biriktiriladi.
+func (h *Hub) publish(payload map[string]interface{}, recipients []string, delivery func(recipientID string) *Delivery) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	unlock := h.lockRecipients(recipients)
+	defer unlock()
+
+	seqs, err := h.appendEvents(payload["type"].(string), data, recipients)
+	if err != nil {
+		return err
+	}
+
+	e := envelope{
+		Kind:       kindBroadcast,
+		Data:       data,
+		Recipients: recipients,
+		Seqs:       seqs,
+	}
+	if delivery != nil {
+		e.Deliveries = make(map[string]*Delivery, len(recipients))
+		for _, id := range recipients {
+			if d := delivery(id); d != nil {
+				e.Deliveries[id] = d
+			}
+		}
+	}
+
+	// Avval boshqa instance'larga: backplane xabarni qabul qilmasa event mahalliy clientlarga ham
+	// yuborilmaydi, xato qaytadi va chaqiruvchi (outbox relay) uni keyinroq qayta yuboradi
+	if err := h.forwardErr(e); err != nil {
+		return err
+	}
+	h.deliver(&e)
+	return nil
+}
+
+// lockRecipients - qabul qiluvchilarning lock'larini o'sish tartibida oladi (deadlock bo'lmasligi uchun)
+func (h *Hub) lockRecipients(recipients []string) func() {
+	stripes := make([]int, 0, len(recipients))
+	for _, id := range recipients {
+		hash := fnv.New32a()
+		hash.Write([]byte(id))
+		stripes = append(stripes, int(hash.Sum32()%publishStripes))
+	}
+	slices.Sort(stripes)
+	stripes = slices.Compact(stripes)
+
+	for _, i := range stripes {
+		h.publishMu[i].Lock()
+	}
+	return func() {
+		for _, i := range stripes {
+			h.publishMu[i].Unlock()
+		}
+	}
+}
+
+// appendEvents - EventStore sozlanmagan bo'lsa event seq'siz (faqat jonli) yuboriladi
+func (h *Hub) appendEvents(eventType string, data []byte, recipients []string) (map[string]int64, error) {
+	if h.Events == nil {
+		return nil, nil
+	}
+
+	userIDs := make([]int64, 0, len(recipients))
+	for _, id := range recipients {
+		if userID, err := strconv.ParseInt(id, 10, 64); err == nil {
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), appendTimeout)
+	defer cancel()
+
+	seqs, err := h.Events.Append(ctx, userIDs, eventType, data)
+	if err != nil {
+		return nil, fmt.Errorf("event log append failed: %w", err)
+	}
+
+	result := make(map[string]int64, len(seqs))
+	for userID, seq := range seqs {
+		result[strconv.FormatInt(userID, 10)] = seq
+	}
+	return result, nil
+}
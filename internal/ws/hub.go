@@ -1,23 +1,97 @@
 package ws
 
 import (
-	"encoding/json"
+	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// deliveryBuffer - yozilishi kutilayotgan "yetkazildi" signallari uchun bufer
+	deliveryBuffer = 1024
+	// presenceBuffer - qayta ishlanishi kutilayotgan presence o'zgarishlari uchun bufer
+	presenceBuffer = 1024
+
+	slowConsumerReason = "slow consumer: send buffer full"
 )
 
+var errSlowConsumer = errors.New(slowConsumerReason)
+
 type Hub struct {
-	mu         sync.RWMutex
-	Clients    map[string]*Client
+	mu sync.RWMutex
+	// Clients - user ID -> ulanish ID -> client; bitta user bir nechta qurilmadan ulanishi mumkin
+	Clients    map[string]map[string]*Client
 	Register   chan *Client
 	Unregister chan *Client
+	// Deliveries - new_message frame client socket'iga yozilganda shu kanalga tushadi
+	Deliveries chan Delivery
+	// PresenceChanges - user online/away/offline bo'lganda shu kanalga tushadi
+	PresenceChanges chan PresenceChange
+	// Events - muhim event'lar shu oqimga seq bilan yoziladi (nil bo'lsa seq'siz yuboriladi)
+	Events EventStore
+	// OnError - hub ichidagi xatolarni log qilish uchun (nil bo'lsa e'tiborsiz qoldiriladi)
+	OnError func(msg string, err error)
+	// OnFrame - client yuborgan har bir frame shu handler'ga beriladi (nil bo'lsa e'tiborsiz qoldiriladi)
+	OnFrame func(c *Client, data []byte)
+	// Config - ulanishlar uchun deadline va hajm cheklovlari (NewClient'dan oldin o'rnatiladi)
+	Config Config
+	// LoadRoom - chat a'zolari indeksida bo'lmagan chat uchun a'zolarni yuklaydi
+	LoadRoom RoomLoader
+	// Backplane - broadcast'larni boshqa API instance'lariga uzatadi (nil bo'lsa faqat shu jarayon ichida)
+	Backplane Backplane
+	// InstanceID - backplane'da shu instance'ni ajratib turadi
+	InstanceID string
+
+	// remotePresence - user ID -> instance ID -> boshqa instance'dagi holat (h.mu bilan himoyalangan)
+	remotePresence map[string]map[string]string
+	// instances - boshqa instance'lardan oxirgi xabar kelgan vaqt (h.mu bilan himoyalangan)
+	instances map[string]time.Time
+
+	// droppedFrames - navbati to'lgani uchun yuborilmagan frame'lar; evictedClients - shu sababli uzilgan ulanishlar
+	droppedFrames  atomic.Uint64
+	evictedClients atomic.Uint64
+	// droppedDeliveries - Deliveries bufer to'lgani uchun yozilmay qolgan "yetkazildi" signallari
+	droppedDeliveries atomic.Uint64
+
+	// rooms - chat ID -> a'zolar (user ID'lar); roomsGen har bir a'zolik o'zgarishida oshadi
+	roomsMu  sync.RWMutex
+	rooms    map[int64][]string
+	roomsGen uint64
+
+	// publishMu - qabul qiluvchi bo'yicha lock'lar (lockRecipients)
+	publishMu [publishStripes]sync.Mutex
+
+	typingMu sync.Mutex
+	typing   map[typingKey]*typingState
 }
 
 func NewHub() *Hub {
 	return &Hub{
-		Register:   make(chan *Client),
-		Unregister: make(chan *Client),
-		Clients:    make(map[string]*Client),
+		Register:        make(chan *Client),
+		Unregister:      make(chan *Client),
+		Clients:         make(map[string]map[string]*Client),
+		Deliveries:      make(chan Delivery, deliveryBuffer),
+		PresenceChanges: make(chan PresenceChange, presenceBuffer),
+		Config:          DefaultConfig(),
+		InstanceID:      uuid.NewString(),
+		remotePresence:  make(map[string]map[string]string),
+		instances:       make(map[string]time.Time),
+		rooms:           make(map[int64][]string),
+		typing:          make(map[typingKey]*typingState),
+	}
+}
+
+// reportDelivery - bufer to'lgan bo'lsa signal tashlab yuboriladi (WritePump bloklanmasligi kerak)
+// va droppedDeliveries hisoblagichida sanaladi
+func (h *Hub) reportDelivery(d Delivery) {
+	select {
+	case h.Deliveries <- d:
+	default:
+		h.droppedDeliveries.Add(1)
 	}
 }
 
@@ -26,125 +100,177 @@ func (h *Hub) Run() {
 		select {
 		case client := <-h.Register:
 			h.mu.Lock()
-			h.Clients[client.ID] = client
+			before, localBefore := h.presenceLocked(client.ID), h.localPresenceLocked(client.ID)
+			conns, ok := h.Clients[client.ID]
+			if !ok {
+				conns = make(map[string]*Client)
+				h.Clients[client.ID] = conns
+			}
+			conns[client.ConnID] = client
+			h.presenceChangedLocked(client.ID, before, localBefore)
 			h.mu.Unlock()
 
 		case client := <-h.Unregister:
 			h.mu.Lock()
-			if _, ok := h.Clients[client.ID]; ok {
-				delete(h.Clients, client.ID)
+			before, localBefore := h.presenceLocked(client.ID), h.localPresenceLocked(client.ID)
+			// Faqat aynan shu ulanish o'chiriladi, userning boshqa qurilmalari ulanib qoladi
+			conns := h.Clients[client.ID]
+			if conns[client.ConnID] == client {
+				delete(conns, client.ConnID)
 				close(client.Send)
+				if len(conns) == 0 {
+					delete(h.Clients, client.ID)
+				}
 			}
+			localAfter := h.presenceChangedLocked(client.ID, before, localBefore)
 			h.mu.Unlock()
+
+			// Typing holati shu instance'da saqlanadi
+			if localAfter == PresenceOffline {
+				go h.stopAllTyping(client.ID)
+			}
 		}
 	}
 }
 
-func (h *Hub) BroadcastChatMessage(chatID int64, chatName, senderID, senderName, content string, recipientIDs []string) {
+func (h *Hub) BroadcastChatMessage(chatID, msgID int64, chatName, senderID, senderName, content string, replyToMessageID *int64, attachments interface{}) error {
 	payload := map[string]interface{}{
-		"type":        "new_message",
-		"chat_id":     chatID,
-		"chat_name":   chatName,
-		"sender_id":   senderID,
-		"sender_name": senderName,
-		"content":     content,
-		"created_at":  time.Now().Format("2006-01-02 15:04:05"),
-	}
-
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return
+		"type":                "new_message",
+		"chat_id":             chatID,
+		"message_id":          msgID,
+		"chat_name":           chatName,
+		"sender_id":           senderID,
+		"sender_name":         senderName,
+		"content":             content,
+		"reply_to_message_id": replyToMessageID,
+		"attachments":         attachments,
+		"created_at":          time.Now().Format("2006-01-02 15:04:05"),
 	}
 
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-
-	for _, id := range recipientIDs {
-
+	return h.publishToChat(chatID, payload, func(id string) *Delivery {
+		// Yuboruvchining boshqa qurilmalari ham xabarni oladi, lekin bu "yetkazildi" hisoblanmaydi
 		if id == senderID {
-			continue
+			return nil
 		}
-
-		if client, ok := h.Clients[id]; ok {
-			select {
-			case client.Send <- data:
-			default:
-
-			}
+		return &Delivery{
+			ChatID:      chatID,
+			MessageID:   msgID,
+			SenderID:    senderID,
+			RecipientID: id,
 		}
-	}
+	})
 }
 
-func (h *Hub) BroadcastReadStatus(chatID int64, readerID string, recipientID string) {
+// BroadcastReadStatus - o'qilganlikni chat a'zolariga, jumladan o'quvchining boshqa qurilmalariga tarqatadi
+func (h *Hub) BroadcastReadStatus(chatID int64, readerID string, lastReadMessageID int64) error {
 	payload := map[string]interface{}{
-		"type":      "messages_read",
-		"chat_id":   chatID,
-		"reader_id": readerID,
-	}
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return
+		"type":                 "messages_read",
+		"chat_id":              chatID,
+		"reader_id":            readerID,
+		"last_read_message_id": lastReadMessageID,
 	}
+	return h.publishToChat(chatID, payload, nil)
+}
+
+// broadcastToRecipients - saqlanmaydigan (seq'siz) event'lar uchun: typing, presence
+func (h *Hub) broadcastToRecipients(recipients []string, data []byte) {
+	e := envelope{Kind: kindBroadcast, Data: data, Recipients: recipients}
+	h.deliver(&e)
+	h.forward(e)
+}
 
+// SendTo - frame'ni faqat bitta ulanishga yuboradi (masalan buyruqqa javob).
+// Ulanish allaqachon yopilgan yoki bufer to'la bo'lsa false qaytadi.
+func (h *Hub) SendTo(c *Client, data []byte) bool {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	if client, ok := h.Clients[recipientID]; ok {
+	if h.Clients[c.ID][c.ConnID] != c {
+		return false
+	}
+
+	select {
+	case c.Send <- Outbound{Data: data}:
+		return true
+	default:
+		return false
+	}
+}
+
+// sendToUser - frame'ni userning barcha ulanishlariga yuboradi. Navbati to'lgan ulanish event'larni
+// jimgina yo'qotmasligi uchun uziladi: client qayta ulanib seq bo'yicha resume qiladi.
+// Chaqiruvchi h.mu ni ushlab turishi kerak.
+func (h *Hub) sendToUser(userID string, out Outbound) {
+	for _, client := range h.Clients[userID] {
 		select {
-		case client.Send <- data:
+		case client.Send <- out:
 		default:
+			h.droppedFrames.Add(1)
+			if client.evict(websocket.CloseTryAgainLater, slowConsumerReason) {
+				h.evictedClients.Add(1)
+				h.reportError("websocket client evicted", errSlowConsumer)
+			}
 		}
 	}
 }
 
-func (h *Hub) broadcastToRecipients(recipients []string, data []byte) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-
-	for _, id := range recipients {
-		if client, ok := h.Clients[id]; ok {
-			select {
-			case client.Send <- data:
-			default:
+// Stats - shu instance'dagi ulanishlar va navbat/bufer to'lishi hisoblagichlari
+type Stats struct {
+	Users             int    `json:"users"`
+	Connections       int    `json:"connections"`
+	DroppedFrames     uint64 `json:"dropped_frames"`
+	EvictedClients    uint64 `json:"evicted_clients"`
+	DroppedDeliveries uint64 `json:"dropped_deliveries"`
+}
 
-			}
-		}
+func (h *Hub) Stats() Stats {
+	h.mu.RLock()
+	stats := Stats{Users: len(h.Clients)}
+	for _, conns := range h.Clients {
+		stats.Connections += len(conns)
 	}
+	h.mu.RUnlock()
+
+	stats.DroppedFrames = h.droppedFrames.Load()
+	stats.EvictedClients = h.evictedClients.Load()
+	stats.DroppedDeliveries = h.droppedDeliveries.Load()
+	return stats
 }
 
 // BroadcastMessageUpdate - xabar tahrirlanganini tarqatadi
-func (h *Hub) BroadcastMessageUpdate(chatID, msgID int64, newText string, recipients []string) {
+func (h *Hub) BroadcastMessageUpdate(chatID, msgID int64, newText, editedAt string) error {
 	payload := map[string]interface{}{
 		"type":         "message_updated",
 		"chat_id":      chatID,
 		"message_id":   msgID,
 		"message_text": newText,
+		"edited_at":    editedAt,
+		"is_edited":    true,
 	}
 
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return
-	}
-	h.broadcastToRecipients(recipients, data)
+	return h.publishToChat(chatID, payload, nil)
 }
 
-// BroadcastMessageDelete - xabar o'chirilganini tarqatadi
-func (h *Hub) BroadcastMessageDelete(chatID, msgID int64, recipients []string) {
-	payload := map[string]interface{}{
+// BroadcastMessageDelete - xabar hamma uchun o'chirilganini tarqatadi
+func (h *Hub) BroadcastMessageDelete(chatID, msgID int64) error {
+	return h.publishToChat(chatID, messageDeletedPayload(chatID, msgID), nil)
+}
+
+// BroadcastMessageHide - xabar faqat user uchun yashirilganini uning barcha qurilmalariga yuboradi
+func (h *Hub) BroadcastMessageHide(chatID, msgID int64, userID string) error {
+	return h.publish(messageDeletedPayload(chatID, msgID), []string{userID}, nil)
+}
+
+func messageDeletedPayload(chatID, msgID int64) map[string]interface{} {
+	return map[string]interface{}{
 		"type":       "message_deleted",
 		"chat_id":    chatID,
 		"message_id": msgID,
 	}
-
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return
-	}
-	h.broadcastToRecipients(recipients, data)
 }
 
-// BroadcastMemberAdded - groupga yangi a'zo qo'shilganini tarqatadi
-func (h *Hub) BroadcastMemberAdded(chatID, userID, addedByID int64, username, addedByName string, recipients []string) {
+// BroadcastMemberAdded - groupga yangi a'zo qo'shilganini tarqatadi (a'zo AddChatMember bilan indeksga qo'shilgan bo'lishi kerak)
+func (h *Hub) BroadcastMemberAdded(chatID, userID, addedByID int64, username, addedByName string) error {
 	payload := map[string]interface{}{
 		"type":          "member_added",
 		"chat_id":       chatID,
@@ -154,15 +280,12 @@ func (h *Hub) BroadcastMemberAdded(chatID, userID, addedByID int64, username, ad
 		"added_by_name": addedByName,
 	}
 
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return
-	}
-	h.broadcastToRecipients(recipients, data)
+	return h.publishToChat(chatID, payload, nil)
 }
 
-// BroadcastChatDelete - chat o'chirilganini tarqatadi
-func (h *Hub) BroadcastChatDelete(chatID, deletedByID int64, deletedByName string, recipients []string) {
+// BroadcastChatDelete - chat o'chirilganini tarqatadi va uni indeksdan olib tashlaydi.
+// Chat bazadan o'chirilgani uchun a'zolar ro'yxati chaqiruvchidan olinadi.
+func (h *Hub) BroadcastChatDelete(chatID, deletedByID int64, deletedByName string, recipients []string) error {
 	payload := map[string]interface{}{
 		"type":            "chat_deleted",
 		"chat_id":         chatID,
@@ -170,9 +293,61 @@ func (h *Hub) BroadcastChatDelete(chatID, deletedByID int64, deletedByName strin
 		"deleted_by_name": deletedByName,
 	}
 
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return
+	h.ForgetChat(chatID)
+	return h.publish(payload, recipients, nil)
+}
+
+// BroadcastReactionAdded - xabarga reaksiya qo'shilganini tarqatadi
+func (h *Hub) BroadcastReactionAdded(chatID, msgID, userID int64, username, emoji string) error {
+	payload := map[string]interface{}{
+		"type":       "reaction_added",
+		"chat_id":    chatID,
+		"message_id": msgID,
+		"user_id":    userID,
+		"username":   username,
+		"emoji":      emoji,
 	}
-	h.broadcastToRecipients(recipients, data)
+
+	return h.publishToChat(chatID, payload, nil)
+}
+
+// BroadcastReactionRemoved - xabardan reaksiya olib tashlanganini tarqatadi
+func (h *Hub) BroadcastReactionRemoved(chatID, msgID, userID int64, emoji string) error {
+	payload := map[string]interface{}{
+		"type":       "reaction_removed",
+		"chat_id":    chatID,
+		"message_id": msgID,
+		"user_id":    userID,
+		"emoji":      emoji,
+	}
+
+	return h.publishToChat(chatID, payload, nil)
+}
+
+// BroadcastMention - mention qilingan userlarga alohida "mentioned" event yuboradi.
+// Bu event chat ovozsiz (muted) bo'lsa ham yuboriladi.
+func (h *Hub) BroadcastMention(chatID, msgID int64, chatName, senderID, senderName, content string, recipients []string) error {
+	payload := map[string]interface{}{
+		"type":        "mentioned",
+		"chat_id":     chatID,
+		"message_id":  msgID,
+		"chat_name":   chatName,
+		"sender_id":   senderID,
+		"sender_name": senderName,
+		"content":     content,
+	}
+
+	return h.publish(payload, recipients, nil)
+}
+
+// BroadcastDelivered - yuboruvchiga xabari qabul qiluvchiga yetkazilganini bildiradi
+func (h *Hub) BroadcastDelivered(chatID, msgID int64, recipientID, senderID string) error {
+	payload := map[string]interface{}{
+		"type":         "message_delivered",
+		"chat_id":      chatID,
+		"message_id":   msgID,
+		"recipient_id": recipientID,
+	}
+
+	return h.publish(payload, []string{senderID}, nil)
 }
@@ -0,0 +1,183 @@
+package ws
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// BackplaneChannel - barcha instance'lar LISTEN qiladigan Postgres kanali
+	BackplaneChannel = "chatx_hub"
+
+	// notifyPayloadLimit - Postgres NOTIFY payload chegarasi 8000 bayt, konvert uchun zaxira qoldiriladi
+	notifyPayloadLimit = 7900
+	// payloadRefPrefix - katta xabar o'rniga yuboriladigan hub_backplane_messages ID'si prefiksi
+	payloadRefPrefix = "#"
+
+	publishBuffer       = 4096
+	backplaneDBTimeout  = 5 * time.Second
+	listenerMinInterval = time.Second
+	listenerMaxInterval = 30 * time.Second
+	listenerPingEvery   = 90 * time.Second
+
+	// storedPayloadTTL - saqlangan katta xabarlar shu vaqtdan keyin o'chiriladi
+	storedPayloadTTL     = 5 * time.Minute
+	storedPayloadCleanup = time.Minute
+)
+
+var ErrBackplaneFull = errors.New("backplane publish buffer is full")
+
+// PostgresBackplane - instance'larni Postgres LISTEN/NOTIFY orqali bog'laydi.
+// Xabarlar bitta goroutine'da navbat bilan NOTIFY qilinadi, shuning uchun tartib saqlanadi.
+type PostgresBackplane struct {
+	db      *sql.DB
+	dsn     string
+	outbox  chan []byte
+	OnError func(msg string, err error)
+}
+
+func NewPostgresBackplane(db *sql.DB, dsn string) *PostgresBackplane {
+	return &PostgresBackplane{
+		db:     db,
+		dsn:    dsn,
+		outbox: make(chan []byte, publishBuffer),
+	}
+}
+
+// Publish - xabarni navbatga qo'yadi; hub'ni DB kutib bloklamaslik uchun darhol qaytadi
+func (b *PostgresBackplane) Publish(msg []byte) error {
+	select {
+	case b.outbox <- msg:
+		return nil
+	default:
+		return ErrBackplaneFull
+	}
+}
+
+func (b *PostgresBackplane) Subscribe(ctx context.Context, handler func(msg []byte)) error {
+	listener := pq.NewListener(b.dsn, listenerMinInterval, listenerMaxInterval, func(_ pq.ListenerEventType, err error) {
+		if err != nil {
+			b.reportError("backplane listener connection failed", err)
+		}
+	})
+	if err := listener.Listen(BackplaneChannel); err != nil {
+		listener.Close()
+		return err
+	}
+
+	go b.sendLoop(ctx)
+	go b.receiveLoop(ctx, listener, handler)
+
+	return nil
+}
+
+func (b *PostgresBackplane) sendLoop(ctx context.Context) {
+	cleanup := time.NewTicker(storedPayloadCleanup)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-b.outbox:
+			if err := b.notify(ctx, msg); err != nil {
+				b.reportError("backplane notify failed", err)
+			}
+		case <-cleanup.C:
+			if err := b.deleteStoredPayloads(ctx); err != nil {
+				b.reportError("backplane cleanup failed", err)
+			}
+		}
+	}
+}
+
+func (b *PostgresBackplane) receiveLoop(ctx context.Context, listener *pq.Listener, handler func(msg []byte)) {
+	defer listener.Close()
+
+	ping := time.NewTicker(listenerPingEvery)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-listener.Notify:
+			// nil - ulanish qayta tiklandi
+			if n == nil {
+				handler(nil)
+				continue
+			}
+
+			msg, err := b.resolve(ctx, n.Extra)
+			if err != nil {
+				b.reportError("backplane payload load failed", err)
+				continue
+			}
+			handler(msg)
+		case <-ping.C:
+			go listener.Ping()
+		}
+	}
+}
+
+// notify - katta xabar avval jadvalga yoziladi, NOTIFY bilan faqat uning ID'si yuboriladi
+func (b *PostgresBackplane) notify(ctx context.Context, msg []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, backplaneDBTimeout)
+	defer cancel()
+
+	payload := string(msg)
+	if len(payload) > notifyPayloadLimit {
+		var id int64
+		query := `INSERT INTO hub_backplane_messages (payload) VALUES ($1) RETURNING id`
+		if err := b.db.QueryRowContext(ctx, query, payload).Scan(&id); err != nil {
+			return err
+		}
+		payload = payloadRefPrefix + strconv.FormatInt(id, 10)
+	}
+
+	_, err := b.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, BackplaneChannel, payload)
+	return err
+}
+
+func (b *PostgresBackplane) resolve(ctx context.Context, payload string) ([]byte, error) {
+	ref, ok := strings.CutPrefix(payload, payloadRefPrefix)
+	if !ok {
+		return []byte(payload), nil
+	}
+
+	id, err := strconv.ParseInt(ref, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, backplaneDBTimeout)
+	defer cancel()
+
+	var stored string
+	query := `SELECT payload FROM hub_backplane_messages WHERE id = $1`
+	if err := b.db.QueryRowContext(ctx, query, id).Scan(&stored); err != nil {
+		return nil, err
+	}
+	return []byte(stored), nil
+}
+
+func (b *PostgresBackplane) deleteStoredPayloads(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, backplaneDBTimeout)
+	defer cancel()
+
+	query := `DELETE FROM hub_backplane_messages WHERE created_at < NOW() - make_interval(secs => $1)`
+	_, err := b.db.ExecContext(ctx, query, storedPayloadTTL.Seconds())
+	return err
+}
+
+func (b *PostgresBackplane) reportError(msg string, err error) {
+	if b.OnError != nil {
+		b.OnError(msg, err)
+	}
+}
@@ -0,0 +1,101 @@
+package ws
+
+import "encoding/json"
+
+const (
+	PresenceOnline  = "online"
+	PresenceAway    = "away"
+	PresenceOffline = "offline"
+)
+
+// PresenceChange - user'ning holati o'zgarganda PresenceChanges kanaliga tushadi
+type PresenceChange struct {
+	UserID string
+	Status string
+}
+
+// Presence - user'ning hozirgi holati barcha instance'lardagi WebSocket ulanishlaridan aniqlanadi
+func (h *Hub) Presence(userID string) string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.presenceLocked(userID)
+}
+
+// presenceLocked - shu va boshqa instance'lardagi ulanishlardan umumiy holat.
+// Chaqiruvchi h.mu ni ushlab turishi kerak.
+func (h *Hub) presenceLocked(userID string) string {
+	status := h.localPresenceLocked(userID)
+	for _, remote := range h.remotePresence[userID] {
+		if remote == PresenceOnline || status == PresenceOffline {
+			status = remote
+		}
+	}
+	return status
+}
+
+// localPresenceLocked - kamida bitta ulanish faol bo'lsa online, hammasi away bo'lsa away.
+// Chaqiruvchi h.mu ni ushlab turishi kerak.
+func (h *Hub) localPresenceLocked(userID string) string {
+	conns := h.Clients[userID]
+	if len(conns) == 0 {
+		return PresenceOffline
+	}
+
+	for _, client := range conns {
+		if !client.away {
+			return PresenceOnline
+		}
+	}
+	return PresenceAway
+}
+
+// SetAway - ulanish o'zini away/online deb belgilaydi (masalan tab yashirilganda)
+func (h *Hub) SetAway(c *Client, away bool) {
+	h.mu.Lock()
+	if h.Clients[c.ID][c.ConnID] != c || c.away == away {
+		h.mu.Unlock()
+		return
+	}
+	before, localBefore := h.presenceLocked(c.ID), h.localPresenceLocked(c.ID)
+	c.away = away
+	h.presenceChangedLocked(c.ID, before, localBefore)
+	h.mu.Unlock()
+}
+
+// presenceChangedLocked - lokal holat o'zgargan bo'lsa boshqa instance'larga, umumiy holat
+// o'zgargan bo'lsa PresenceChanges'ga xabar beradi. Chaqiruvchi h.mu ni ushlab turishi kerak.
+func (h *Hub) presenceChangedLocked(userID, before, localBefore string) string {
+	after, localAfter := h.presenceLocked(userID), h.localPresenceLocked(userID)
+	if localAfter != localBefore {
+		h.forward(envelope{Kind: kindPresence, UserID: userID, Status: localAfter})
+	}
+	if after != before {
+		h.reportPresence(PresenceChange{UserID: userID, Status: after})
+	}
+	return localAfter
+}
+
+// reportPresence - bufer to'lgan bo'lsa o'zgarish tashlab yuboriladi, Run bloklanmasligi kerak
+func (h *Hub) reportPresence(p PresenceChange) {
+	select {
+	case h.PresenceChanges <- p:
+	default:
+	}
+}
+
+func (h *Hub) BroadcastPresence(userID, status string, lastSeenAt *string, recipientIDs []string) {
+	payload := map[string]interface{}{
+		"type":         "presence_changed",
+		"user_id":      userID,
+		"status":       status,
+		"last_seen_at": lastSeenAt,
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+
+	h.broadcastToRecipients(recipientIDs, data)
+}
@@ -0,0 +1,43 @@
+package ws
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter - oddiy token bucket: burst ta frame'ga ruxsat, keyin har interval'da bittadan
+type RateLimiter struct {
+	mu       sync.Mutex
+	tokens   float64
+	burst    float64
+	perToken time.Duration
+	last     time.Time
+}
+
+func NewRateLimiter(burst int, perToken time.Duration) *RateLimiter {
+	return &RateLimiter{
+		tokens:   float64(burst),
+		burst:    float64(burst),
+		perToken: perToken,
+		last:     time.Now(),
+	}
+}
+
+func (l *RateLimiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += float64(now.Sub(l.last)) / float64(l.perToken)
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+
+	l.tokens--
+	return true
+}
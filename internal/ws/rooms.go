@@ -0,0 +1,107 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// roomLoadTimeout - broadcast paytida chat a'zolarini bazadan yuklash uchun vaqt
+const roomLoadTimeout = 5 * time.Second
+
+var errNoRoomLoader = errors.New("hub room loader is not configured")
+
+// RoomLoader - chat a'zolarining user ID'larini bazadan yuklaydi
+type RoomLoader func(ctx context.Context, chatID int64) ([]string, error)
+
+// ChatMembers - chat a'zolari indeksdan olinadi, indeksda bo'lmasa LoadRoom orqali yuklanadi.
+// Qaytgan slice boshqa chaqiruvlar bilan umumiy, uni o'zgartirish mumkin emas.
+func (h *Hub) ChatMembers(ctx context.Context, chatID int64) ([]string, error) {
+	h.roomsMu.RLock()
+	members, ok := h.rooms[chatID]
+	gen := h.roomsGen
+	h.roomsMu.RUnlock()
+	if ok {
+		return members, nil
+	}
+
+	if h.LoadRoom == nil {
+		return nil, errNoRoomLoader
+	}
+	members, err := h.LoadRoom(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Yuklash paytida a'zolik o'zgargan bo'lsa natija eskirgan bo'lishi mumkin, u keshlanmaydi.
+	// Bo'sh natija ham keshlanmaydi: chat hali yaratilmagan yoki o'chirilgan bo'lishi mumkin.
+	if len(members) > 0 {
+		h.roomsMu.Lock()
+		if h.roomsGen == gen {
+			h.rooms[chatID] = members
+		}
+		h.roomsMu.Unlock()
+	}
+
+	return members, nil
+}
+
+// AddChatMember - chatga qo'shilgan a'zoni indeksga qo'shadi (boshqa instance'larda indeks qayta yuklanadi)
+func (h *Hub) AddChatMember(chatID int64, userID string) {
+	h.updateRoom(chatID, func(members []string) []string {
+		if slices.Contains(members, userID) {
+			return members
+		}
+		return append(slices.Clip(members), userID)
+	})
+	h.forward(envelope{Kind: kindRoom, ChatID: chatID})
+}
+
+// RemoveChatMember - chatdan chiqqan a'zoni indeksdan olib tashlaydi
+func (h *Hub) RemoveChatMember(chatID int64, userID string) {
+	h.updateRoom(chatID, func(members []string) []string {
+		return slices.DeleteFunc(slices.Clone(members), func(id string) bool { return id == userID })
+	})
+	h.forward(envelope{Kind: kindRoom, ChatID: chatID})
+}
+
+// ForgetChat - o'chirilgan chatni indeksdan olib tashlaydi
+func (h *Hub) ForgetChat(chatID int64) {
+	h.invalidateRoom(chatID)
+	h.forward(envelope{Kind: kindRoom, ChatID: chatID})
+}
+
+// updateRoom - indeksdagi a'zolar ro'yxatini nusxa ustida o'zgartiradi; chat indeksda bo'lmasa
+// keyingi ChatMembers uni bazadan yangi holatda yuklaydi
+func (h *Hub) updateRoom(chatID int64, update func(members []string) []string) {
+	h.roomsMu.Lock()
+	defer h.roomsMu.Unlock()
+
+	h.roomsGen++
+	if members, ok := h.rooms[chatID]; ok {
+		h.rooms[chatID] = update(members)
+	}
+}
+
+func (h *Hub) invalidateRoom(chatID int64) {
+	h.roomsMu.Lock()
+	defer h.roomsMu.Unlock()
+
+	h.roomsGen++
+	delete(h.rooms, chatID)
+}
+
+// publishToChat - event'ni chatning barcha a'zolariga yuboradi
+func (h *Hub) publishToChat(chatID int64, payload map[string]interface{}, delivery func(recipientID string) *Delivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), roomLoadTimeout)
+	defer cancel()
+
+	members, err := h.ChatMembers(ctx, chatID)
+	if err != nil {
+		return fmt.Errorf("chat members load failed: %w", err)
+	}
+
+	return h.publish(payload, members, delivery)
+}
@@ -0,0 +1,87 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// sseKeepAlive - proxy'lar bo'sh oqimni uzib qo'ymasligi uchun shu oraliqda izoh yoziladi
+const sseKeepAlive = 25 * time.Second
+
+// WriteSSE - bitta frame'ni Server-Sent Events formatida yozadi. seq "id:" sifatida beriladi,
+// shunda brauzer qayta ulanganda uni Last-Event-ID header'ida qaytaradi.
+func WriteSSE(w io.Writer, seq int64, data []byte) error {
+	var buf bytes.Buffer
+	if seq > 0 {
+		buf.WriteString("id: ")
+		buf.WriteString(strconv.FormatInt(seq, 10))
+		buf.WriteByte('\n')
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// SSEPump - WritePump'ning SSE varianti: Send'dagi frame'larni w ga yozib, har biridan keyin flush qiladi.
+// afterSeq'gacha bo'lgan seq'li frame'lar allaqachon qayta yuborilgan, ular tashlab yuboriladi.
+// ctx tugaganda, client hub'dan chiqarilganda yoki navbati to'lib uzilganda qaytadi.
+func (c *Client) SSEPump(ctx context.Context, w io.Writer, rc *http.ResponseController, afterSeq int64) error {
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-c.evicted:
+			return errSlowConsumer
+
+		case message, ok := <-c.Send:
+			if !ok {
+				return nil
+			}
+			if message.Seq > 0 && message.Seq <= afterSeq {
+				continue
+			}
+
+			if err := c.writeSSE(rc, func() error { return WriteSSE(w, message.Seq, message.Data) }); err != nil {
+				return err
+			}
+
+			if message.Delivery != nil {
+				c.Hub.reportDelivery(*message.Delivery)
+			}
+
+		case <-keepAlive.C:
+			err := c.writeSSE(rc, func() error {
+				_, err := io.WriteString(w, ": keep-alive\n\n")
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeSSE - yozish va flush WriteTimeout bilan cheklanadi, qotib qolgan ulanish pump'ni ushlab turmasligi uchun
+func (c *Client) writeSSE(rc *http.ResponseController, write func() error) error {
+	if err := rc.SetWriteDeadline(time.Now().Add(c.Hub.Config.WriteTimeout)); err != nil {
+		return err
+	}
+	if err := write(); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
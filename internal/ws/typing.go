@@ -0,0 +1,95 @@
+package ws
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TypingTTL - client shu vaqt ichida typing_started'ni yangilamasa, server o'zi typing_stopped yuboradi
+const TypingTTL = 6 * time.Second
+
+type typingKey struct {
+	chatID int64
+	userID string
+}
+
+type typingState struct {
+	timer      *time.Timer
+	recipients []string
+}
+
+// StartTyping - user yozayotganini chatning boshqa a'zolariga bildiradi.
+// Takroriy chaqiruvlar faqat expiry taymerini yangilaydi, event qayta yuborilmaydi.
+func (h *Hub) StartTyping(chatID int64, userID string, recipients []string) {
+	key := typingKey{chatID: chatID, userID: userID}
+
+	h.typingMu.Lock()
+	if state, ok := h.typing[key]; ok {
+		state.timer.Reset(TypingTTL)
+		state.recipients = recipients
+		h.typingMu.Unlock()
+		return
+	}
+
+	h.typing[key] = &typingState{
+		timer:      time.AfterFunc(TypingTTL, func() { h.StopTyping(chatID, userID) }),
+		recipients: recipients,
+	}
+	h.typingMu.Unlock()
+
+	h.broadcastTyping("typing_started", chatID, userID, recipients)
+}
+
+// StopTyping - typing holatini tugatadi; user yozmayotgan bo'lsa hech narsa qilmaydi
+func (h *Hub) StopTyping(chatID int64, userID string) {
+	key := typingKey{chatID: chatID, userID: userID}
+
+	h.typingMu.Lock()
+	state, ok := h.typing[key]
+	if ok {
+		state.timer.Stop()
+		delete(h.typing, key)
+	}
+	h.typingMu.Unlock()
+
+	if ok {
+		h.broadcastTyping("typing_stopped", chatID, userID, state.recipients)
+	}
+}
+
+// stopAllTyping - ulanish uzilganda user'ning barcha chatlardagi typing holatini tugatadi
+func (h *Hub) stopAllTyping(userID string) {
+	h.typingMu.Lock()
+	var chats []int64
+	for key := range h.typing {
+		if key.userID == userID {
+			chats = append(chats, key.chatID)
+		}
+	}
+	h.typingMu.Unlock()
+
+	for _, chatID := range chats {
+		h.StopTyping(chatID, userID)
+	}
+}
+
+func (h *Hub) broadcastTyping(eventType string, chatID int64, userID string, recipients []string) {
+	payload := map[string]interface{}{
+		"type":    eventType,
+		"chat_id": chatID,
+		"user_id": userID,
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+
+	others := make([]string, 0, len(recipients))
+	for _, id := range recipients {
+		if id != userID {
+			others = append(others, id)
+		}
+	}
+	h.broadcastToRecipients(others, data)
+}
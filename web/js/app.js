@@ -19,8 +19,24 @@ const state = {
   manualWsClose: false,
   wsConnected: false,
   health: null,
+  typing: new Map(),
+  lastTypingSentAt: 0,
+  wsRequestSeq: 0,
+  wsPending: new Map(),
+  lastSeq: null,
+  resuming: false,
+  sse: null,
+  wsFailures: 0,
 };
 
+const WS_PROTOCOL_VERSION = 1;
+const WS_COMMAND_TIMEOUT_MS = 10000;
+// WS_FALLBACK_AFTER - shuncha WebSocket urinishi ochilmasdan yopilsa SSE'ga o'tiladi
+const WS_FALLBACK_AFTER = 2;
+const SYNC_PAGE_LIMIT = 500;
+const TYPING_RESEND_MS = 3000;
+const TYPING_EXPIRE_MS = 7000;
+
 const els = {
   authScreen: document.getElementById("authScreen"),
   appShell: document.getElementById("appShell"),
@@ -123,6 +139,10 @@ function bindEvents() {
   els.groupForm.addEventListener("submit", submitGroupChat);
   els.editGroupForm.addEventListener("submit", submitGroupUpdate);
   els.composerForm.addEventListener("submit", submitMessage);
+  els.messageInput.addEventListener("input", notifyTyping);
+  document.addEventListener("visibilitychange", () => {
+    sendSocketCommand("set_presence", { status: document.hidden ? "away" : "online" });
+  });
 
   els.chatList.addEventListener("click", (event) => {
     const item = event.target.closest("[data-chat-id]");
@@ -214,6 +234,7 @@ function clearSessionState() {
   state.messages = [];
   state.members = [];
   state.memberCandidates = [];
+  state.lastSeq = null;
   disconnectWebSocket();
   renderSessionBadge();
   renderWsBadge();
@@ -410,6 +431,7 @@ async function refreshChats() {
 
 async function selectChat(chatID) {
   if (!ensureSession()) return;
+  stopTyping();
   state.selectedChatId = chatID;
   renderChatList();
   renderChatMeta();
@@ -417,8 +439,8 @@ async function selectChat(chatID) {
   setMessagesLoading();
 
   try {
-    const rawMessages = await apiRequest(`/chats/${chatID}/messages`);
-    state.messages = asArray(rawMessages).map((message) => normalizeMessage(message, chatID));
+    const page = await apiRequest(`/chats/${chatID}/messages`);
+    state.messages = asArray(page?.messages).map((message) => normalizeMessage(message, chatID));
     renderMessages();
     markCurrentChatAsRead(true).catch(() => {});
   } catch (error) {
@@ -432,7 +454,10 @@ async function markCurrentChatAsRead(silent) {
   if (!ensureSession() || !state.selectedChatId) return;
   await apiRequest(`/messages/chats/${state.selectedChatId}/read`, { method: "PATCH" });
   const chat = getSelectedChat();
-  if (chat) chat.unreadCount = 0;
+  if (chat) {
+    chat.unreadCount = 0;
+    chat.unreadMentions = 0;
+  }
   renderChatList();
   if (!silent) toast("Chat read holatiga o'tkazildi.", "ok");
 }
@@ -444,13 +469,16 @@ async function submitMessage(event) {
 
   const text = els.messageInput.value.trim();
   if (!text) return;
+  stopTyping();
 
   try {
-    const created = await apiRequest("/messages", {
-      method: "POST",
-      body: { chat_id: state.selectedChatId, message_text: text },
-    });
-    state.messages.push(normalizeMessage(created, state.selectedChatId));
+    const body = { chat_id: state.selectedChatId, message_text: text };
+    // WebSocket ulangan bo'lsa buyruq orqali, aks holda REST orqali yuboriladi
+    const created = socketReady()
+      ? (await requestSocketCommand("send_message", body)).message
+      : await apiRequest("/messages", { method: "POST", body });
+    const createdMessage = normalizeMessage(created, state.selectedChatId);
+    if (!state.messages.some((item) => item.id === createdMessage.id)) state.messages.push(createdMessage);
     els.messageInput.value = "";
     renderMessages(true);
     await refreshChats();
@@ -472,6 +500,7 @@ async function editMessage(messageID) {
   try {
     await apiRequest(`/messages/${messageID}`, { method: "PATCH", body: { message_text: text } });
     message.content = text;
+    message.isEdited = true;
     renderMessages();
     toast("Xabar yangilandi.", "ok");
   } catch (error) {
@@ -671,26 +700,63 @@ async function removeMember(userID) {
   }
 }
 
-function connectWebSocket() {
+// fetchStreamTicket - WebSocket/SSE uchun bir martalik ticket: JWT URL'ga (va proxy log'lariga) tushmaydi
+async function fetchStreamTicket() {
+  const data = await apiRequest("/ws/ticket", { method: "POST" });
+  return data.ticket;
+}
+
+// retryStream - ticket olinmasa (tarmoq xatosi) ulanish keyinroq qayta uriniladi
+function retryStream(connect) {
+  if (state.manualWsClose || !state.token) return;
+  if (state.wsTimer) clearTimeout(state.wsTimer);
+  state.wsTimer = setTimeout(connect, 2200);
+}
+
+async function connectWebSocket() {
   if (!state.token) return;
   state.manualWsClose = false;
   if (state.ws) state.ws.close();
 
+  let ticket;
+  try {
+    ticket = await fetchStreamTicket();
+  } catch {
+    retryStream(connectWebSocket);
+    return;
+  }
+  if (state.manualWsClose || !state.token) return;
+
   const protocol = window.location.protocol === "https:" ? "wss" : "ws";
-  const wsURL = `${protocol}://${window.location.host}${API_BASE}/ws?token=${encodeURIComponent(state.token)}`;
+  const wsURL = `${protocol}://${window.location.host}${API_BASE}/ws?ticket=${encodeURIComponent(ticket)}`;
   state.ws = new WebSocket(wsURL);
+  let opened = false;
 
   state.ws.onopen = () => {
+    opened = true;
+    state.wsFailures = 0;
     state.wsConnected = true;
     renderWsBadge();
+    if (document.hidden) sendSocketCommand("set_presence", { status: "away" });
+    resumeEvents().catch(() => {});
   };
 
   state.ws.onclose = () => {
     state.wsConnected = false;
     renderWsBadge();
     state.ws = null;
+    state.wsPending.forEach((pending) => {
+      clearTimeout(pending.timer);
+      pending.reject(new Error("WebSocket uzildi."));
+    });
+    state.wsPending.clear();
+    if (!opened) state.wsFailures += 1;
     if (!state.manualWsClose && state.token) {
       if (state.wsTimer) clearTimeout(state.wsTimer);
+      if (state.wsFailures >= WS_FALLBACK_AFTER) {
+        connectEventStream();
+        return;
+      }
       state.wsTimer = setTimeout(connectWebSocket, 2200);
     }
   };
@@ -709,6 +775,53 @@ function connectWebSocket() {
   };
 }
 
+// connectEventStream - WebSocket upgrade ishlamasa (masalan proxy uzib qo'ysa) event'lar SSE orqali olinadi.
+// Bu rejimda buyruqlar REST orqali yuboriladi, typing va presence buyruqlari yuborilmaydi.
+// Ticket bir martalik bo'lgani uchun EventSource'ning o'z qayta ulanishi ishlamaydi: xatoda oqim yopilib,
+// yangi ticket va last_event_id bilan qayta ochiladi.
+async function connectEventStream() {
+  if (!state.token) return;
+  state.manualWsClose = false;
+  if (state.sse) state.sse.close();
+
+  let ticket;
+  try {
+    ticket = await fetchStreamTicket();
+  } catch {
+    retryStream(connectEventStream);
+    return;
+  }
+  if (state.manualWsClose || !state.token) return;
+
+  const params = new URLSearchParams({ ticket });
+  if (state.lastSeq !== null) params.set("last_event_id", String(state.lastSeq));
+  const source = new EventSource(`${API_BASE}/events?${params}`);
+  state.sse = source;
+
+  source.onopen = () => {
+    state.wsConnected = true;
+    renderWsBadge();
+    resumeEvents().catch(() => {});
+  };
+
+  source.onerror = () => {
+    state.wsConnected = false;
+    renderWsBadge();
+    source.close();
+    if (state.sse !== source) return;
+    state.sse = null;
+    retryStream(connectEventStream);
+  };
+
+  source.onmessage = async (event) => {
+    try {
+      await handleSocketEvent(JSON.parse(event.data));
+    } catch {
+      toast("SSE event parsing xatosi.", "error");
+    }
+  };
+}
+
 function disconnectWebSocket() {
   if (state.wsTimer) {
     clearTimeout(state.wsTimer);
@@ -719,18 +832,170 @@ function disconnectWebSocket() {
     state.ws.close();
     state.ws = null;
   }
+  if (state.sse) {
+    state.sse.close();
+    state.sse = null;
+  }
+  state.wsFailures = 0;
   state.wsConnected = false;
 }
 
+function socketReady() {
+  return Boolean(state.ws && state.ws.readyState === WebSocket.OPEN);
+}
+
+// sendSocketCommand - javob kutilmaydigan buyruq (typing, presence)
+function sendSocketCommand(type, data) {
+  if (!socketReady()) return;
+  state.ws.send(JSON.stringify({ v: WS_PROTOCOL_VERSION, type, data }));
+}
+
+// requestSocketCommand - ack yoki error kelguncha kutadigan buyruq
+function requestSocketCommand(type, data) {
+  if (!socketReady()) return Promise.reject(new Error("WebSocket ulanmagan."));
+  const id = `c-${++state.wsRequestSeq}`;
+  return new Promise((resolve, reject) => {
+    const timer = setTimeout(() => {
+      state.wsPending.delete(id);
+      reject(new Error("WebSocket javobi kelmadi."));
+    }, WS_COMMAND_TIMEOUT_MS);
+    state.wsPending.set(id, { resolve, reject, timer });
+    state.ws.send(JSON.stringify({ v: WS_PROTOCOL_VERSION, id, type, data }));
+  });
+}
+
+function settleSocketCommand(payload) {
+  const pending = state.wsPending.get(payload.id);
+  if (!pending) {
+    if (payload.type === "error") toast(payload.error?.message || "WebSocket xatosi.", "error");
+    return;
+  }
+  state.wsPending.delete(payload.id);
+  clearTimeout(pending.timer);
+  if (payload.type === "ack") pending.resolve(payload.result);
+  else pending.reject(new Error(payload.error?.message || "WebSocket xatosi."));
+}
+
+function notifyTyping() {
+  if (!state.selectedChatId || !els.messageInput.value.trim()) return stopTyping();
+  const now = Date.now();
+  if (now - state.lastTypingSentAt < TYPING_RESEND_MS) return;
+  state.lastTypingSentAt = now;
+  sendSocketCommand("typing_started", { chat_id: state.selectedChatId });
+}
+
+function stopTyping() {
+  if (!state.lastTypingSentAt || !state.selectedChatId) return;
+  state.lastTypingSentAt = 0;
+  sendSocketCommand("typing_stopped", { chat_id: state.selectedChatId });
+}
+
+function setTyping(chatID, userID, active) {
+  let users = state.typing.get(chatID);
+  clearTimeout(users?.get(userID));
+  if (active) {
+    if (!users) state.typing.set(chatID, (users = new Map()));
+    users.set(userID, setTimeout(() => setTyping(chatID, userID, false), TYPING_EXPIRE_MS));
+  } else if (users) {
+    users.delete(userID);
+    if (!users.size) state.typing.delete(chatID);
+  }
+  if (chatID === state.selectedChatId) renderChatMeta();
+}
+
+// resumeEvents - uzilish paytida o'tkazib yuborilgan event'larni seq bo'yicha qayta oladi.
+// Sessiyadagi birinchi ulanishda faqat joriy seq eslab qolinadi (holat REST orqali yuklangan).
+async function resumeEvents() {
+  if (state.resuming || (!socketReady() && !state.sse)) return;
+  state.resuming = true;
+  try {
+    if (state.lastSeq === null) {
+      const page = await apiRequest("/sync?limit=1");
+      state.lastSeq = Number(page?.last_seq || 0);
+      return;
+    }
+
+    for (let attempt = 0; attempt < 20; attempt += 1) {
+      const result = socketReady()
+        ? await requestSocketCommand("resume", { since: state.lastSeq })
+        : await syncEvents();
+      if (result.truncated) {
+        state.lastSeq = Number(result.last_seq || 0);
+        await refreshAllData();
+        if (state.selectedChatId) await selectChat(state.selectedChatId);
+        return;
+      }
+      if (!result.has_more && state.lastSeq >= Number(result.last_seq || 0)) return;
+    }
+  } finally {
+    state.resuming = false;
+  }
+}
+
+// syncEvents - SSE rejimidagi resume: event'lar REST orqali olinib, jonli event kabi qayta ishlanadi
+async function syncEvents() {
+  const page = await apiRequest(`/sync?since=${state.lastSeq}&limit=${SYNC_PAGE_LIMIT}`);
+  if (!page?.truncated) {
+    for (const event of page?.events || []) await handleSocketEvent(event);
+  }
+  return page || {};
+}
+
+// trackSeq - takroriy event'larni tashlab yuboradi, oraliq topilsa resume boshlaydi
+function trackSeq(payload) {
+  const seq = Number(payload.seq);
+  if (!seq || state.lastSeq === null) return true;
+  if (seq <= state.lastSeq) return false;
+  if (seq > state.lastSeq + 1) {
+    if (!state.resuming) resumeEvents().catch(() => {});
+    return false;
+  }
+  state.lastSeq = seq;
+  return true;
+}
+
 async function handleSocketEvent(payload) {
   const type = payload?.type;
   if (!type) return;
+  if (payload.seq && !trackSeq(payload)) return;
+
+  if (type === "ack" || type === "error") {
+    settleSocketCommand(payload);
+    return;
+  }
+
+  // SSE qayta ulanganda kerakli event'lar allaqachon o'chirilgan bo'lsa keladi
+  if (type === "resync_required") {
+    state.lastSeq = Number(payload.last_seq || 0);
+    await refreshAllData();
+    if (state.selectedChatId) await selectChat(state.selectedChatId);
+    return;
+  }
+
+  if (type === "presence_changed") {
+    const userID = Number(payload.user_id);
+    const presence = normalizePresence(payload);
+    state.chats.filter((chat) => chat.peerId === userID).forEach((chat) => {
+      chat.presence = presence;
+    });
+    state.members.filter((member) => member.id === userID).forEach((member) => {
+      member.presence = presence;
+    });
+    renderChatMeta();
+    if (state.members.length) renderMembersList();
+    return;
+  }
+
+  if (type === "typing_started" || type === "typing_stopped") {
+    setTyping(Number(payload.chat_id), Number(payload.user_id), type === "typing_started");
+    return;
+  }
 
   if (type === "new_message") {
     const chatID = Number(payload.chat_id);
     const senderID = Number(payload.sender_id);
     const message = {
-      id: Date.now(),
+      id: Number(payload.message_id) || Date.now(),
       chatId: chatID,
       senderId: senderID,
       senderName: normalizeUsername(payload.sender_name) || getUserDisplayName(senderID),
@@ -739,10 +1004,20 @@ async function handleSocketEvent(payload) {
       isRead: false,
     };
 
+    const isOwn = senderID === state.currentUserId;
     if (chatID === state.selectedChatId) {
+      // O'zimiz boshqa qurilmadan yuborgan xabar yoki HTTP javobi bilan kelgan takror
+      if (state.messages.some((item) => item.id === message.id)) return;
       state.messages.push(message);
       renderMessages(true);
-      await markCurrentChatAsRead(true);
+      if (!isOwn) await markCurrentChatAsRead(true);
+    } else if (isOwn) {
+      const chat = state.chats.find((item) => item.chatId === chatID);
+      if (chat) {
+        chat.lastMessage = message.content;
+        chat.lastMessageAt = message.createdAt;
+        renderChatList();
+      }
     } else {
       const chat = state.chats.find((item) => item.chatId === chatID);
       if (chat) {
@@ -756,6 +1031,18 @@ async function handleSocketEvent(payload) {
       toast(`${message.senderName}: ${clip(message.content, 48)}`, "info");
     }
   }
+  if (type === "mentioned") {
+    const chatID = Number(payload.chat_id);
+    if (chatID !== state.selectedChatId) {
+      const chat = state.chats.find((item) => item.chatId === chatID);
+      if (chat) {
+        chat.unreadMentions = (chat.unreadMentions || 0) + 1;
+        renderChatList();
+      }
+    }
+    toast(`${normalizeUsername(payload.sender_name) || "Kimdir"} sizni eslatdi: ${clip(payload.content || "", 48)}`, "info");
+  }
+
   if (type === "message_updated") {
     const chatID = Number(payload.chat_id);
     const messageID = Number(payload.message_id);
@@ -763,6 +1050,7 @@ async function handleSocketEvent(payload) {
     const message = state.messages.find((item) => item.id === messageID);
     if (!message) return;
     message.content = payload.message_text || message.content;
+    message.isEdited = true;
     renderMessages();
     toast("Xabar tahrirlandi.", "info");
   }
@@ -779,10 +1067,20 @@ async function handleSocketEvent(payload) {
   if (type === "messages_read") {
     const chatID = Number(payload.chat_id);
     const readerID = Number(payload.reader_id);
-    if (chatID !== state.selectedChatId || readerID === state.currentUserId) return;
+    if (readerID === state.currentUserId) {
+      const chat = state.chats.find((item) => item.chatId === chatID);
+      if (chat && (chat.unreadCount || chat.unreadMentions)) {
+        chat.unreadCount = 0;
+        chat.unreadMentions = 0;
+        renderChatList();
+      }
+      return;
+    }
+    if (chatID !== state.selectedChatId) return;
+    const lastReadID = Number(payload.last_read_message_id) || Infinity;
     let hasUpdates = false;
     state.messages.forEach((message) => {
-      if (message.senderId === state.currentUserId && !message.isRead) {
+      if (message.senderId === state.currentUserId && !message.isRead && message.id <= lastReadID) {
         message.isRead = true;
         hasUpdates = true;
       }
@@ -791,6 +1089,16 @@ async function handleSocketEvent(payload) {
     toast(`${getUserDisplayName(readerID)} xabarlarni o'qidi.`, "info");
   }
 
+  if (type === "message_delivered") {
+    const chatID = Number(payload.chat_id);
+    const messageID = Number(payload.message_id);
+    if (chatID !== state.selectedChatId) return;
+    const message = state.messages.find((item) => item.id === messageID);
+    if (!message || message.isDelivered) return;
+    message.isDelivered = true;
+    renderMessages(false);
+  }
+
   if (type === "member_added") {
     const chatID = Number(payload.chat_id);
     const addedUserID = Number(payload.user_id);
@@ -928,12 +1236,13 @@ function renderWsBadge() {
     return;
   }
 
+  const transport = state.sse ? "SSE" : "WS";
   if (state.wsConnected) {
     els.wsBadge.className = "pill ok";
-    els.wsBadge.textContent = `WS: ulangan (${getCurrentUserDisplayName()})`;
+    els.wsBadge.textContent = `${transport}: ulangan (${getCurrentUserDisplayName()})`;
   } else {
     els.wsBadge.className = "pill offline";
-    els.wsBadge.textContent = "WS: uzilgan";
+    els.wsBadge.textContent = `${transport}: uzilgan`;
   }
 }
 
@@ -963,6 +1272,7 @@ function renderChatList() {
       <div class="chat-last">${escapeHTML(chat.lastMessage || "Xabar yo'q")}</div>
       <div class="chat-foot">
         <span class="chat-time">${formatDate(chat.lastMessageAt || chat.joinedAt)}</span>
+        ${chat.unreadMentions > 0 ? `<span class="unread mention">@</span>` : ""}
         ${chat.unreadCount > 0 ? `<span class="unread">${chat.unreadCount}</span>` : ""}
       </div>
     `;
@@ -1008,6 +1318,9 @@ function renderChatMeta() {
 
   els.activeChatName.textContent = chat.chatName;
   els.activeChatInfo.textContent = `${chat.chatType.toUpperCase()} | role: ${chat.userRole || "-"} | unread: ${chat.unreadCount || 0}`;
+  if (chat.presence) els.activeChatInfo.textContent += ` | ${presenceLabel(chat.presence)}`;
+  const typingNames = [...(state.typing.get(chat.chatId)?.keys() || [])].map((id) => getUserDisplayName(id));
+  if (typingNames.length) els.activeChatInfo.textContent += ` | ${typingNames.join(", ")} yozmoqda...`;
   const isGroup = chat.chatType === "group";
   els.membersBtn.disabled = !isGroup;
   els.editGroupBtn.disabled = !isGroup;
@@ -1034,12 +1347,13 @@ function renderMessages(shouldScroll = true) {
       <div class="message-head">
         <span class="message-author">${escapeHTML(message.senderName || getUserDisplayName(message.senderId))}</span>
         <span class="message-time">
-          ${formatDate(message.createdAt)}
+          ${formatDate(message.createdAt)}${message.isEdited ? " (tahrirlangan)" : ""}
           ${mine ? renderMessageStatus(message) : ""}
         </span>
       </div>
-      <p class="message-body">${escapeHTML(message.content)}</p>
-      ${mine ? `
+      <p class="message-body">${message.isDeleted ? "<em>Xabar o'chirilgan</em>" : escapeHTML(message.content)}</p>
+      ${!message.isDeleted && message.attachments.length ? renderAttachments(message.attachments) : ""}
+      ${mine && !message.isDeleted ? `
         <div class="message-tools">
           <button type="button" class="tool-btn edit" data-action="edit-message" data-message-id="${message.id}">Tahrirlash</button>
           <button type="button" class="tool-btn remove" data-action="delete-message" data-message-id="${message.id}">O'chirish</button>
@@ -1050,6 +1364,7 @@ function renderMessages(shouldScroll = true) {
   });
 
   els.messagesList.appendChild(fragment);
+  loadThumbnails(els.messagesList);
   if (shouldScroll) els.messagesList.scrollTop = els.messagesList.scrollHeight;
 }
 
@@ -1079,7 +1394,7 @@ function renderMembersList() {
     item.innerHTML = `
       <div>
         <strong>${escapeHTML(member.username)}</strong>
-        <div class="chat-time">${escapeHTML(member.email || "")}</div>
+        <div class="chat-time">${escapeHTML([member.email, presenceLabel(member.presence)].filter(Boolean).join(" | "))}</div>
       </div>
       ${canRemove ? `<button type="button" class="btn mini danger" data-action="remove-member" data-user-id="${member.id}">Chiqarish</button>` : `<span class="chat-time">siz</span>`}
     `;
@@ -1189,14 +1504,32 @@ function normalizeChat(raw) {
     lastMessage: String(raw.last_message ?? raw.lastMessage ?? raw.LastMessage ?? ""),
     lastMessageAt: raw.last_message_at ?? raw.lastMessageAt ?? raw.LastMessageAt ?? "",
     unreadCount: Number(raw.unread_count ?? raw.unreadCount ?? raw.UnreadCount ?? 0),
+    unreadMentions: Number(raw.unread_mentions ?? raw.unreadMentions ?? 0),
+    peerId: Number(raw.peer_id ?? 0) || null,
+    presence: raw.presence ? normalizePresence(raw.presence) : null,
+  };
+}
+
+function normalizePresence(raw) {
+  return {
+    status: String(raw?.status || "offline"),
+    lastSeenAt: raw?.last_seen_at || null,
   };
 }
 
+function presenceLabel(presence) {
+  if (!presence) return "";
+  if (presence.status === "online") return "online";
+  if (presence.status === "away") return "away";
+  return presence.lastSeenAt ? `oxirgi marta: ${formatDate(presence.lastSeenAt)}` : "offline";
+}
+
 function normalizeUser(raw) {
   return {
     id: Number(raw.id ?? raw.ID ?? 0),
     username: String(raw.username ?? raw.user_name ?? raw.UserName ?? `User #${raw.id || "?"}`),
     email: String(raw.email ?? raw.Email ?? ""),
+    presence: raw.presence ? normalizePresence(raw.presence) : null,
   };
 }
 
@@ -1210,11 +1543,52 @@ function normalizeMessage(raw, fallbackChatID) {
     content: String(raw.content ?? raw.message_text ?? raw.messageText ?? raw.MessageText ?? ""),
     createdAt: raw.created_at ?? raw.createdAt ?? raw.CreatedAt ?? new Date().toISOString(),
     isRead: Boolean(raw.is_read ?? raw.isRead ?? raw.IsRead ?? false),
+    isEdited: Boolean(raw.is_edited ?? raw.isEdited ?? false),
+    isDeleted: Boolean(raw.is_deleted ?? raw.isDeleted ?? false),
+    attachments: Array.isArray(raw.attachments) ? raw.attachments : [],
   };
 }
+function renderAttachments(attachments) {
+  const items = attachments
+    .map((file) => {
+      const thumb = (file.thumbnails || []).find((t) => t.size === "small") || (file.thumbnails || [])[0];
+      if (thumb) {
+        return `<li><img class="attachment-thumb" alt="${escapeHTML(file.file_name || "image")}" width="${thumb.width}" height="${thumb.height}" data-thumb-url="${escapeHTML(thumb.url)}"></li>`;
+      }
+      return `<li>${escapeHTML(file.file_name || "file")} (${Math.ceil((file.size_bytes || 0) / 1024)} KB)</li>`;
+    })
+    .join("");
+  return `<ul class="message-attachments">${items}</ul>`;
+}
+
+// Thumbnail'lar Authorization header bilan yuklanadi, shuning uchun <img src> ga to'g'ridan-to'g'ri berilmaydi
+const thumbnailCache = new Map();
+
+async function loadThumbnails(root) {
+  const images = root.querySelectorAll("img[data-thumb-url]");
+  for (const img of images) {
+    const url = img.dataset.thumbUrl;
+    try {
+      if (!thumbnailCache.has(url)) {
+        const response = await fetch(url, { headers: { Authorization: `Bearer ${state.token}` } });
+        if (!response.ok) continue;
+        thumbnailCache.set(url, URL.createObjectURL(await response.blob()));
+      }
+      img.src = thumbnailCache.get(url);
+    } catch {
+      // thumbnail yuklanmasa xabar matni baribir ko'rinadi
+    }
+  }
+}
+
 function renderMessageStatus(message) {
-  const read = Boolean(message?.isRead);
-  return `<span class="message-status ${read ? "read" : "sent"}" title="${read ? "O'qilgan" : "Yuborilgan"}">${read ? "&#10003;&#10003;" : "&#10003;"}</span>`;
+  if (message?.isRead) {
+    return `<span class="message-status read" title="O'qilgan">&#10003;&#10003;</span>`;
+  }
+  if (message?.isDelivered) {
+    return `<span class="message-status delivered" title="Yetkazilgan">&#10003;&#10003;</span>`;
+  }
+  return `<span class="message-status sent" title="Yuborilgan">&#10003;</span>`;
 }
 
 function parseTokenClaims(token) {